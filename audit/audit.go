@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/Rexa/Gate/common"
+)
+
+// Log writes audit entries as json lines and rotates the file once it reaches maxSize,
+// keeping maxFiles rotated files as path.1 (newest) to path.N (oldest).
+// A nil Log discards everything.
+type Log struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+	mu       sync.Mutex
+}
+
+func New(path string, maxSize int64, maxFiles int) *Log {
+	if path == "" {
+		return nil
+	}
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	return &Log{path: path, maxSize: maxSize, maxFiles: maxFiles}
+}
+
+func (l *Log) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// open must be called with mu held.
+func (l *Log) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// rotate must be called with mu held.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	for n := l.maxFiles - 1; n >= 1; n-- {
+		if err := os.Rename(l.rotatedPath(n), l.rotatedPath(n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(l.path, l.rotatedPath(1)); err != nil {
+		return err
+	}
+
+	return l.open()
+}
+
+func (l *Log) Write(entry *common.AuditEntry) error {
+	if l == nil {
+		return nil
+	}
+
+	line, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		if err = l.open(); err != nil {
+			return err
+		}
+	}
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err = l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// Query returns the matching entries oldest first, limited to the most recent ones when a limit is set.
+func (l *Log) Query(request *common.AuditLogRequest) ([]*common.AuditEntry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []*common.AuditEntry
+	for n := l.maxFiles; n >= 0; n-- {
+		path := l.path
+		if n > 0 {
+			path = l.rotatedPath(n)
+		}
+
+		matched, err := l.read(path, request)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, matched...)
+	}
+
+	if limit := int(request.GetLimit()); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+func (l *Log) read(path string, request *common.AuditLogRequest) ([]*common.AuditEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*common.AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry := &common.AuditEntry{}
+		if err = (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(scanner.Bytes(), entry); err != nil {
+			// a line cut short by a crash shouldn't hide the rest of the log
+			continue
+		}
+
+		if matches(entry, request) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func matches(entry *common.AuditEntry, request *common.AuditLogRequest) bool {
+	if request.GetSince() > 0 && entry.GetTime() < request.GetSince() {
+		return false
+	}
+	if request.GetUntil() > 0 && entry.GetTime() > request.GetUntil() {
+		return false
+	}
+	return request.GetMethod() == "" || entry.GetMethod() == request.GetMethod()
+}
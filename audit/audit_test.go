@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l := New(path, 300, 2)
+
+	for i := int64(1); i <= 10; i++ {
+		method := "SyncUser"
+		if i%2 == 0 {
+			method = "Start"
+		}
+		entry := &common.AuditEntry{Time: i, Ip: "10.0.0.1", Key: "default", Method: method, Code: "OK"}
+		if err := l.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() > 300 {
+		t.Fatalf("the log should have been rotated: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatal("only two rotated files should be kept")
+	}
+
+	entries, err := l.Query(&common.AuditLogRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].GetTime() != 10 {
+		t.Fatalf("the newest entry should come last, got %v", entries)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].GetTime() <= entries[i-1].GetTime() {
+			t.Fatal("entries should be ordered oldest first")
+		}
+	}
+
+	entries, err = l.Query(&common.AuditLogRequest{Since: 7, Until: 9, Method: "Start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].GetTime() != 8 {
+		t.Fatalf("expected only the start at 8, got %v", entries)
+	}
+
+	entries, err = l.Query(&common.AuditLogRequest{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].GetTime() != 9 {
+		t.Fatalf("the limit should keep the most recent entries, got %v", entries)
+	}
+
+	var disabled *Log
+	if err = disabled.Write(&common.AuditEntry{}); err != nil {
+		t.Fatal("a disabled log should discard entries")
+	}
+}
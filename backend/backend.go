@@ -8,18 +8,33 @@ import (
 
 type Backend interface {
 	Started() bool
+	Healthy() bool
 	Version() string
 	Logs() chan string
 	Restart() error
 	Shutdown()
-	SyncUser(context.Context, *common.User) error
-	SyncUsers(context.Context, []*common.User) error
+	SyncUser(context.Context, *common.User) (bool, error)
+	SyncUsers(context.Context, []*common.User) (*common.SyncUsersResponse, error)
+	RemoveUsers(context.Context, []string) (*common.RemoveUsersResponse, error)
+	ListUsers(context.Context) (*common.LoadedUsersResponse, error)
+	GetUser(context.Context, string) (*common.LoadedUser, error)
 	GetSysStats(context.Context) (*common.BackendStatsResponse, error)
 	GetStats(context.Context, *common.StatRequest) (*common.StatResponse, error)
 	GetUserOnlineStats(context.Context, string) (*common.OnlineStatResponse, error)
 	GetUserOnlineIpListStats(context.Context, string) (*common.StatsOnlineIpListResponse, error)
+	GetRestrictedUsers(context.Context) (*common.RestrictedUsersResponse, error)
+	GetUpcomingExpirations(context.Context, int64) (*common.ExpirationsResponse, error)
+	GetEnforcementEvents(context.Context, int64) (*common.EnforcementEventsResponse, error)
+	GetPolicies(context.Context) (*common.Policies, error)
+	SetPolicies(context.Context, *common.Policies) error
 }
 
 type ConfigKey struct{}
 
 type UsersKey struct{}
+
+// RestartHookKey carries the RestartHook a backend calls when it restarts the core on its own.
+type RestartHookKey struct{}
+
+// RestartHook is told why the core was restarted and which users caused it.
+type RestartHook func(reason string, users []string)
@@ -3,11 +3,15 @@ package api
 import (
 	"github.com/google/uuid"
 	"github.com/xtls/xray-core/common/serial"
+	"github.com/xtls/xray-core/infra/conf"
+	"github.com/xtls/xray-core/proxy/http"
 	"github.com/xtls/xray-core/proxy/shadowsocks"
 	"github.com/xtls/xray-core/proxy/shadowsocks_2022"
+	"github.com/xtls/xray-core/proxy/socks"
 	"github.com/xtls/xray-core/proxy/trojan"
 	"github.com/xtls/xray-core/proxy/vless"
 	"github.com/xtls/xray-core/proxy/vmess"
+	"github.com/xtls/xray-core/proxy/wireguard"
 
 	"github.com/Rexa/Gate/common"
 )
@@ -48,7 +52,7 @@ func NewVmessAccount(user *common.User) (*VmessAccount, error) {
 	return &VmessAccount{
 		BaseAccount: BaseAccount{
 			Email: user.GetEmail(),
-			Level: 0,
+			Level: user.GetLevel(),
 		},
 		ID: id,
 	}, nil
@@ -72,7 +76,7 @@ func NewVlessAccount(user *common.User) (*VlessAccount, error) {
 	return &VlessAccount{
 		BaseAccount: BaseAccount{
 			Email: user.GetEmail(),
-			Level: 0,
+			Level: user.GetLevel(),
 		},
 		ID:   id,
 		Flow: user.GetProxies().GetVless().GetFlow(),
@@ -92,7 +96,7 @@ func NewTrojanAccount(user *common.User) *TrojanAccount {
 	return &TrojanAccount{
 		BaseAccount: BaseAccount{
 			Email: user.GetEmail(),
-			Level: 0,
+			Level: user.GetLevel(),
 		},
 		Password: user.GetProxies().GetTrojan().GetPassword(),
 	}
@@ -141,7 +145,7 @@ func NewShadowsocksAccount(user *common.User) *ShadowsocksAccount {
 	return &ShadowsocksAccount{
 		BaseAccount: BaseAccount{
 			Email: user.GetEmail(),
-			Level: 0,
+			Level: user.GetLevel(),
 		},
 		Password: user.GetProxies().GetShadowsocks().GetPassword(),
 	}
@@ -170,7 +174,7 @@ func NewShadowsocksTcpAccount(user *common.User) *ShadowsocksTcpAccount {
 		ShadowsocksAccount: ShadowsocksAccount{
 			BaseAccount: BaseAccount{
 				Email: user.GetEmail(),
-				Level: 0,
+				Level: user.GetLevel(),
 			},
 			Password: user.GetProxies().GetShadowsocks().GetPassword(),
 		},
@@ -178,10 +182,104 @@ func NewShadowsocksTcpAccount(user *common.User) *ShadowsocksTcpAccount {
 	}
 }
 
+// SocksAccount is a username/password account on a socks inbound, the username is the user email
+// so traffic stats stay keyed by email.
+type SocksAccount struct {
+	BaseAccount
+	Username string `json:"user"`
+	Password string `json:"pass"`
+}
+
+func (sa *SocksAccount) Message() (*serial.TypedMessage, error) {
+	return ToTypedMessage(&socks.Account{Username: sa.Username, Password: sa.Password})
+}
+
+func NewSocksAccount(user *common.User) *SocksAccount {
+	return &SocksAccount{
+		BaseAccount: BaseAccount{
+			Email: user.GetEmail(),
+			Level: user.GetLevel(),
+		},
+		Username: user.GetEmail(),
+		Password: user.GetProxies().GetSocks().GetPassword(),
+	}
+}
+
+// HttpAccount is a username/password account on an http inbound, the username is the user email.
+type HttpAccount struct {
+	BaseAccount
+	Username string `json:"user"`
+	Password string `json:"pass"`
+}
+
+func (ha *HttpAccount) Message() (*serial.TypedMessage, error) {
+	return ToTypedMessage(&http.Account{Username: ha.Username, Password: ha.Password})
+}
+
+func NewHttpAccount(user *common.User) *HttpAccount {
+	return &HttpAccount{
+		BaseAccount: BaseAccount{
+			Email: user.GetEmail(),
+			Level: user.GetLevel(),
+		},
+		Username: user.GetEmail(),
+		Password: user.GetProxies().GetHttp().GetPassword(),
+	}
+}
+
+// WireguardAccount is a peer on a wireguard inbound, the email only identifies it on the node.
+type WireguardAccount struct {
+	BaseAccount
+	PublicKey    string   `json:"publicKey"`
+	PreSharedKey string   `json:"preSharedKey,omitempty"`
+	AllowedIPs   []string `json:"allowedIPs,omitempty"`
+}
+
+func (wa *WireguardAccount) Message() (*serial.TypedMessage, error) {
+	publicKey, err := conf.ParseWireGuardKey(wa.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var preSharedKey string
+	if wa.PreSharedKey != "" {
+		if preSharedKey, err = conf.ParseWireGuardKey(wa.PreSharedKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return ToTypedMessage(&wireguard.PeerConfig{PublicKey: publicKey, PreSharedKey: preSharedKey, AllowedIps: wa.AllowedIPs})
+}
+
+func NewWireguardAccount(user *common.User) (*WireguardAccount, error) {
+	peer := user.GetProxies().GetWireguard()
+	if _, err := conf.ParseWireGuardKey(peer.GetPublicKey()); err != nil {
+		return nil, err
+	}
+	if peer.GetPresharedKey() != "" {
+		if _, err := conf.ParseWireGuardKey(peer.GetPresharedKey()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WireguardAccount{
+		BaseAccount: BaseAccount{
+			Email: user.GetEmail(),
+			Level: user.GetLevel(),
+		},
+		PublicKey:    peer.GetPublicKey(),
+		PreSharedKey: peer.GetPresharedKey(),
+		AllowedIPs:   peer.GetAllowedIps(),
+	}, nil
+}
+
 type ProxySettings struct {
 	Vmess           *VmessAccount
 	Vless           *VlessAccount
 	Trojan          *TrojanAccount
 	Shadowsocks     *ShadowsocksTcpAccount
 	Shadowsocks2022 *ShadowsocksAccount
+	Socks           *SocksAccount
+	Http            *HttpAccount
+	Wireguard       *WireguardAccount
 }
@@ -1,9 +1,12 @@
 package xray
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
@@ -21,6 +24,9 @@ const (
 	Vless       = "vless"
 	Trojan      = "trojan"
 	Shadowsocks = "shadowsocks"
+	Socks       = "socks"
+	Http        = "http"
+	Wireguard   = "wireguard"
 )
 
 type Config struct {
@@ -81,7 +87,7 @@ func (i *Inbound) syncUsers(users []*common.User) {
 				clients = append(clients, account)
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Vless:
 		clients := make([]*api.VlessAccount, 0, len(users))
@@ -98,7 +104,7 @@ func (i *Inbound) syncUsers(users []*common.User) {
 				clients = append(clients, &newAccount)
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Trojan:
 		clients := make([]*api.TrojanAccount, 0, len(users))
@@ -110,7 +116,7 @@ func (i *Inbound) syncUsers(users []*common.User) {
 				clients = append(clients, api.NewTrojanAccount(user))
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Shadowsocks:
 		method, methodOk := i.Settings["method"].(string)
@@ -126,7 +132,7 @@ func (i *Inbound) syncUsers(users []*common.User) {
 					clients = append(clients, &newAccount)
 				}
 			}
-			i.Settings["clients"] = clients
+			i.Settings[i.clientsKey()] = clients
 
 		} else {
 			clients := make([]*api.ShadowsocksTcpAccount, 0, len(users))
@@ -138,8 +144,84 @@ func (i *Inbound) syncUsers(users []*common.User) {
 					clients = append(clients, api.NewShadowsocksTcpAccount(user))
 				}
 			}
-			i.Settings["clients"] = clients
+			i.Settings[i.clientsKey()] = clients
 		}
+
+	case Socks:
+		clients := make([]*api.SocksAccount, 0, len(users))
+		for _, user := range users {
+			if user.GetProxies().GetSocks() == nil {
+				continue
+			}
+			if slices.Contains(user.Inbounds, i.Tag) {
+				clients = append(clients, api.NewSocksAccount(user))
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+		if len(clients) > 0 {
+			i.requirePasswordAuth()
+		}
+
+	case Http:
+		clients := make([]*api.HttpAccount, 0, len(users))
+		for _, user := range users {
+			if user.GetProxies().GetHttp() == nil {
+				continue
+			}
+			if slices.Contains(user.Inbounds, i.Tag) {
+				clients = append(clients, api.NewHttpAccount(user))
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Wireguard:
+		clients := make([]*api.WireguardAccount, 0, len(users))
+		for _, user := range users {
+			if user.GetProxies().GetWireguard() == nil {
+				continue
+			}
+			account, err := api.NewWireguardAccount(user)
+			if err != nil {
+				log.Println("error for user", user.GetEmail(), ":", err)
+				continue
+			}
+			if slices.Contains(user.Inbounds, i.Tag) {
+				clients = append(clients, account)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+	}
+}
+
+// clientsKey returns the settings key holding the inbound users.
+func (i *Inbound) clientsKey() string {
+	switch i.Protocol {
+	case Socks, Http:
+		return "accounts"
+	case Wireguard:
+		return "peers"
+	default:
+		return "clients"
+	}
+}
+
+// requirePasswordAuth makes a socks inbound check the accounts it is given,
+// with the default noauth it would stay an open proxy.
+func (i *Inbound) requirePasswordAuth() {
+	if auth, _ := i.Settings["auth"].(string); auth != "password" {
+		log.Printf("inbound %s: socks auth %q replaced with password to enforce user accounts", i.Tag, auth)
+		i.Settings["auth"] = "password"
+	}
+}
+
+// managesUsers reports whether the inbound accepts users through the handler api.
+// Socks, http and wireguard inbounds only load their accounts on start.
+func (i *Inbound) managesUsers() bool {
+	switch i.Protocol {
+	case Socks, Http, Wireguard:
+		return false
+	default:
+		return true
 	}
 }
 
@@ -150,7 +232,7 @@ func (i *Inbound) updateUser(account api.Account) {
 	email := account.GetEmail()
 	switch account.(type) {
 	case *api.VmessAccount:
-		clients, ok := i.Settings["clients"].([]*api.VmessAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.VmessAccount)
 		if !ok {
 			clients = []*api.VmessAccount{}
 		}
@@ -162,10 +244,10 @@ func (i *Inbound) updateUser(account api.Account) {
 			}
 		}
 
-		i.Settings["clients"] = append(clients, account.(*api.VmessAccount))
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.VmessAccount))
 
 	case *api.VlessAccount:
-		clients, ok := i.Settings["clients"].([]*api.VlessAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.VlessAccount)
 		if !ok {
 			clients = []*api.VlessAccount{}
 		}
@@ -177,10 +259,10 @@ func (i *Inbound) updateUser(account api.Account) {
 			}
 		}
 
-		i.Settings["clients"] = append(clients, account.(*api.VlessAccount))
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.VlessAccount))
 
 	case *api.TrojanAccount:
-		clients, ok := i.Settings["clients"].([]*api.TrojanAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.TrojanAccount)
 		if !ok {
 			clients = []*api.TrojanAccount{}
 		}
@@ -192,10 +274,10 @@ func (i *Inbound) updateUser(account api.Account) {
 			}
 		}
 
-		i.Settings["clients"] = append(clients, account.(*api.TrojanAccount))
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.TrojanAccount))
 
 	case *api.ShadowsocksTcpAccount:
-		clients, ok := i.Settings["clients"].([]*api.ShadowsocksTcpAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.ShadowsocksTcpAccount)
 		if !ok {
 			clients = []*api.ShadowsocksTcpAccount{}
 		}
@@ -207,10 +289,10 @@ func (i *Inbound) updateUser(account api.Account) {
 			}
 		}
 
-		i.Settings["clients"] = append(clients, account.(*api.ShadowsocksTcpAccount))
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.ShadowsocksTcpAccount))
 
 	case *api.ShadowsocksAccount:
-		clients, ok := i.Settings["clients"].([]*api.ShadowsocksAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.ShadowsocksAccount)
 		if !ok {
 			clients = []*api.ShadowsocksAccount{}
 		}
@@ -224,7 +306,53 @@ func (i *Inbound) updateUser(account api.Account) {
 
 		method := i.Settings["method"].(string)
 		newAccount := checkShadowsocks2022(method, *account.(*api.ShadowsocksAccount))
-		i.Settings["clients"] = append(clients, &newAccount)
+		i.Settings[i.clientsKey()] = append(clients, &newAccount)
+
+	case *api.SocksAccount:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.SocksAccount)
+		if !ok {
+			clients = []*api.SocksAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
+		}
+
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.SocksAccount))
+		i.requirePasswordAuth()
+
+	case *api.HttpAccount:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.HttpAccount)
+		if !ok {
+			clients = []*api.HttpAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
+		}
+
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.HttpAccount))
+
+	case *api.WireguardAccount:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.WireguardAccount)
+		if !ok {
+			clients = []*api.WireguardAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
+		}
+
+		i.Settings[i.clientsKey()] = append(clients, account.(*api.WireguardAccount))
 
 	default:
 		return
@@ -237,7 +365,7 @@ func (i *Inbound) removeUser(email string) {
 
 	switch Protocol(i.Protocol) {
 	case Vmess:
-		clients, ok := i.Settings["clients"].([]*api.VmessAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.VmessAccount)
 		if !ok {
 			clients = []*api.VmessAccount{}
 		}
@@ -248,10 +376,10 @@ func (i *Inbound) removeUser(email string) {
 				break
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Vless:
-		clients, ok := i.Settings["clients"].([]*api.VlessAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.VlessAccount)
 		if !ok {
 			clients = []*api.VlessAccount{}
 		}
@@ -262,10 +390,10 @@ func (i *Inbound) removeUser(email string) {
 				break
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Trojan:
-		clients, ok := i.Settings["clients"].([]*api.TrojanAccount)
+		clients, ok := i.Settings[i.clientsKey()].([]*api.TrojanAccount)
 		if !ok {
 			clients = []*api.TrojanAccount{}
 		}
@@ -276,12 +404,12 @@ func (i *Inbound) removeUser(email string) {
 				break
 			}
 		}
-		i.Settings["clients"] = clients
+		i.Settings[i.clientsKey()] = clients
 
 	case Shadowsocks:
 		method, methodOk := i.Settings["method"].(string)
 		if methodOk && strings.HasPrefix(method, "2022-blake3") {
-			clients, ok := i.Settings["clients"].([]*api.ShadowsocksAccount)
+			clients, ok := i.Settings[i.clientsKey()].([]*api.ShadowsocksAccount)
 			if !ok {
 				clients = []*api.ShadowsocksAccount{}
 			}
@@ -292,10 +420,10 @@ func (i *Inbound) removeUser(email string) {
 					break
 				}
 			}
-			i.Settings["clients"] = clients
+			i.Settings[i.clientsKey()] = clients
 
 		} else {
-			clients, ok := i.Settings["clients"].([]*api.ShadowsocksTcpAccount)
+			clients, ok := i.Settings[i.clientsKey()].([]*api.ShadowsocksTcpAccount)
 			if !ok {
 				clients = []*api.ShadowsocksTcpAccount{}
 			}
@@ -306,13 +434,184 @@ func (i *Inbound) removeUser(email string) {
 					break
 				}
 			}
-			i.Settings["clients"] = clients
+			i.Settings[i.clientsKey()] = clients
+		}
+	case Socks:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.SocksAccount)
+		if !ok {
+			clients = []*api.SocksAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Http:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.HttpAccount)
+		if !ok {
+			clients = []*api.HttpAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Wireguard:
+		clients, ok := i.Settings[i.clientsKey()].([]*api.WireguardAccount)
+		if !ok {
+			clients = []*api.WireguardAccount{}
+		}
+
+		for x, client := range clients {
+			if client.Email == email {
+				clients = append(clients[:x], clients[x+1:]...)
+				break
+			}
 		}
+		i.Settings[i.clientsKey()] = clients
 	default:
 		return
 	}
 }
 
+// clients returns the accounts currently held in the inbound client list, keyed by email.
+func (i *Inbound) clients() map[string]api.Account {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	accounts := make(map[string]api.Account)
+	switch clients := i.Settings[i.clientsKey()].(type) {
+	case []*api.VmessAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.VlessAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.TrojanAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.ShadowsocksTcpAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.ShadowsocksAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.SocksAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.HttpAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	case []*api.WireguardAccount:
+		for _, client := range clients {
+			accounts[client.Email] = client
+		}
+	}
+	return accounts
+}
+
+// setClients replaces the inbound client list with the given accounts.
+// Accounts that don't match the inbound protocol are skipped.
+func (i *Inbound) setClients(accounts []api.Account) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	switch i.Protocol {
+	case Vmess:
+		clients := make([]*api.VmessAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.VmessAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Vless:
+		clients := make([]*api.VlessAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.VlessAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Trojan:
+		clients := make([]*api.TrojanAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.TrojanAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Shadowsocks:
+		method, methodOk := i.Settings["method"].(string)
+		if methodOk && strings.HasPrefix(method, "2022-blake3") {
+			clients := make([]*api.ShadowsocksAccount, 0, len(accounts))
+			for _, account := range accounts {
+				if client, ok := account.(*api.ShadowsocksAccount); ok {
+					clients = append(clients, client)
+				}
+			}
+			i.Settings[i.clientsKey()] = clients
+
+		} else {
+			clients := make([]*api.ShadowsocksTcpAccount, 0, len(accounts))
+			for _, account := range accounts {
+				if client, ok := account.(*api.ShadowsocksTcpAccount); ok {
+					clients = append(clients, client)
+				}
+			}
+			i.Settings[i.clientsKey()] = clients
+		}
+
+	case Socks:
+		clients := make([]*api.SocksAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.SocksAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+		if len(clients) > 0 {
+			i.requirePasswordAuth()
+		}
+
+	case Http:
+		clients := make([]*api.HttpAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.HttpAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+
+	case Wireguard:
+		clients := make([]*api.WireguardAccount, 0, len(accounts))
+		for _, account := range accounts {
+			if client, ok := account.(*api.WireguardAccount); ok {
+				clients = append(clients, client)
+			}
+		}
+		i.Settings[i.clientsKey()] = clients
+	}
+}
+
 type Stats struct{}
 
 func (c *Config) ToBytes() ([]byte, error) {
@@ -406,22 +705,20 @@ func (c *Config) ApplyAPI(apiPort int) (err error) {
 
 func (c *Config) checkPolicy() {
 	if c.Policy == nil {
-		c.Policy = &conf.PolicyConfig{Levels: make(map[uint32]*conf.Policy)}
-		c.Policy.Levels[0] = &conf.Policy{StatsUserUplink: true, StatsUserDownlink: true}
+		c.Policy = &conf.PolicyConfig{}
+	}
+	if c.Policy.Levels == nil {
+		c.Policy.Levels = make(map[uint32]*conf.Policy)
+	}
+	if _, ok := c.Policy.Levels[0]; !ok {
 		// StatsUserOnline is not set, which will default to false
-	} else {
-		if c.Policy.Levels == nil {
-			c.Policy.Levels = make(map[uint32]*conf.Policy)
-		}
+		c.Policy.Levels[0] = &conf.Policy{}
+	}
 
-		zero, ok := c.Policy.Levels[0]
-		if !ok {
-			c.Policy.Levels[0] = &conf.Policy{StatsUserUplink: true, StatsUserDownlink: true}
-		} else {
-			zero.StatsUserDownlink = true
-			zero.StatsUserUplink = true
-			// Don't modify StatsUserOnline, respect the value that's already there
-		}
+	for _, level := range c.Policy.Levels {
+		level.StatsUserDownlink = true
+		level.StatsUserUplink = true
+		// Don't modify StatsUserOnline, respect the value that's already there
 	}
 
 	if c.Policy.System == nil {
@@ -437,6 +734,46 @@ func (c *Config) checkPolicy() {
 	}
 }
 
+// ensureLevel adds a policy for a user level that is not defined yet, so its traffic is still counted.
+// It returns true if the policy changed.
+func (c *Config) ensureLevel(level uint32) bool {
+	c.checkPolicy()
+	if _, ok := c.Policy.Levels[level]; ok {
+		return false
+	}
+	c.Policy.Levels[level] = &conf.Policy{
+		StatsUserUplink:   true,
+		StatsUserDownlink: true,
+		StatsUserOnline:   c.Policy.Levels[0].StatsUserOnline,
+	}
+	return true
+}
+
+// enableOnlineStats turns on online stats for every user level, the ip limit watcher relies on them.
+// It reports whether any level had them off.
+func (c *Config) enableOnlineStats() bool {
+	c.checkPolicy()
+	var changed bool
+	for _, level := range c.Policy.Levels {
+		if !level.StatsUserOnline {
+			level.StatsUserOnline = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// ensureOnlineStats enables online stats once one of the users has an ip limit,
+// it reports whether the core has to be restarted to pick them up.
+func (c *Config) ensureOnlineStats(users ...*common.User) bool {
+	for _, user := range users {
+		if user.GetMaxIps() > 0 {
+			return c.enableOnlineStats()
+		}
+	}
+	return false
+}
+
 func (c *Config) RemoveLogFiles() (accessFile, errorFile string) {
 	accessFile = c.LogConfig.AccessLog
 	c.LogConfig.AccessLog = ""
@@ -446,6 +783,47 @@ func (c *Config) RemoveLogFiles() (accessFile, errorFile string) {
 	return accessFile, errorFile
 }
 
+// Hash identifies the non-user parts of the config, client lists of managed inbounds are left out
+// so two panels sending the same config with different users get the same hash.
+func (c *Config) Hash() (string, error) {
+	stripped := *c
+	stripped.InboundConfigs = make([]*Inbound, 0, len(c.InboundConfigs))
+
+	var excluded []string
+	for _, i := range c.InboundConfigs {
+		i.mu.RLock()
+		inbound := &Inbound{
+			Tag:            i.Tag,
+			Listen:         i.Listen,
+			Port:           i.Port,
+			Protocol:       i.Protocol,
+			Settings:       maps.Clone(i.Settings),
+			StreamSettings: i.StreamSettings,
+			Sniffing:       i.Sniffing,
+			Allocation:     i.Allocation,
+		}
+		if i.exclude {
+			excluded = append(excluded, i.Tag)
+		} else {
+			delete(inbound.Settings, i.clientsKey())
+		}
+		i.mu.RUnlock()
+
+		stripped.InboundConfigs = append(stripped.InboundConfigs, inbound)
+	}
+
+	b, err := json.Marshal(struct {
+		Config  *Config  `json:"config"`
+		Exclude []string `json:"exclude"`
+	}{&stripped, excluded})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func NewXRayConfig(config string, exclude []string) (*Config, error) {
 	var xrayConfig Config
 	err := json.Unmarshal([]byte(config), &xrayConfig)
@@ -0,0 +1,43 @@
+package xray
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestConfigHash(t *testing.T) {
+	const raw = `{"inbounds": [{"tag": "vmess-in", "protocol": "vmess", "port": 1000, "settings": {"clients": []}}]}`
+
+	hash := func(config string, exclude []string, users ...*common.User) string {
+		c, err := NewXRayConfig(config, exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.syncUsers(users)
+		h, err := c.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	user := &common.User{
+		Email:    "hash@example.com",
+		Proxies:  &common.Proxy{Vmess: &common.Vmess{Id: uuid.NewString()}},
+		Inbounds: []string{"vmess-in"},
+	}
+
+	base := hash(raw, nil)
+	if hash(raw, nil, user) != base {
+		t.Error("users should not change the config hash")
+	}
+	if hash(raw, []string{"vmess-in"}) == base {
+		t.Error("excluded inbounds should change the config hash")
+	}
+	if hash(`{"inbounds": [{"tag": "vmess-in", "protocol": "vmess", "port": 2000, "settings": {"clients": []}}]}`, nil) == base {
+		t.Error("a different port should change the config hash")
+	}
+}
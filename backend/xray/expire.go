@@ -0,0 +1,139 @@
+package xray
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+)
+
+// trackExpiry registers the expiry time of a synced user.
+// Users that are already expired are restricted right away so they never reach the core.
+func (x *Xray) trackExpiry(user *common.User) {
+	email := user.GetEmail()
+	expireAt := user.GetExpireAt()
+
+	x.restrictMu.Lock()
+	if expireAt > 0 {
+		x.expirations[email] = expireAt
+	} else {
+		delete(x.expirations, email)
+	}
+	x.restrictMu.Unlock()
+
+	if expireAt > 0 && expireAt <= time.Now().Unix() {
+		x.restrict(&common.RestrictedUser{
+			Email:    email,
+			Reason:   common.RestrictionReason_Expired,
+			ExpireAt: expireAt,
+		})
+	} else {
+		x.lift(email, common.RestrictionReason_Expired)
+	}
+
+	x.rescheduleExpirations()
+}
+
+func (x *Xray) untrackExpiry(email string) {
+	x.restrictMu.Lock()
+	delete(x.expirations, email)
+	x.restrictMu.Unlock()
+
+	x.lift(email, common.RestrictionReason_Expired)
+	x.rescheduleExpirations()
+}
+
+func (x *Xray) rescheduleExpirations() {
+	select {
+	case x.expireSignal <- struct{}{}:
+	default:
+	}
+}
+
+// scheduleExpirations sleeps until the next user expires, it wakes up early when the schedule changes.
+func (x *Xray) scheduleExpirations(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		next := x.expireUsers(ctx)
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-x.expireSignal:
+		case <-timer.C:
+		}
+	}
+}
+
+// expireUsers cuts off every user whose expiry time has passed and returns the next expiry time.
+func (x *Xray) expireUsers(baseCtx context.Context) time.Time {
+	now := time.Now().Unix()
+
+	var expired []*common.RestrictedUser
+	var next int64
+
+	x.restrictMu.Lock()
+	for email, expireAt := range x.expirations {
+		if expireAt <= now {
+			expired = append(expired, &common.RestrictedUser{
+				Email:    email,
+				Reason:   common.RestrictionReason_Expired,
+				ExpireAt: expireAt,
+			})
+		} else if next == 0 || expireAt < next {
+			next = expireAt
+		}
+	}
+	x.restrictMu.Unlock()
+
+	for _, user := range expired {
+		if !x.restrict(user) {
+			continue
+		}
+		log.Printf("user %s expired, removing from inbounds", user.GetEmail())
+
+		ctx, cancel := context.WithTimeout(baseCtx, time.Second*5)
+		x.cutOff(ctx, user.GetEmail())
+		cancel()
+	}
+
+	if next == 0 {
+		return time.Time{}
+	}
+	return time.Unix(next, 0)
+}
+
+func (x *Xray) GetUpcomingExpirations(_ context.Context, within int64) (*common.ExpirationsResponse, error) {
+	now := time.Now().Unix()
+
+	x.restrictMu.Lock()
+	response := &common.ExpirationsResponse{}
+	for email, expireAt := range x.expirations {
+		if expireAt <= now || (within > 0 && expireAt > now+within) {
+			continue
+		}
+		response.Users = append(response.Users, &common.UserExpiration{Email: email, ExpireAt: expireAt})
+	}
+	x.restrictMu.Unlock()
+
+	slices.SortFunc(response.Users, func(a, b *common.UserExpiration) int {
+		if c := cmp.Compare(a.GetExpireAt(), b.GetExpireAt()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetEmail(), b.GetEmail())
+	})
+
+	return response, nil
+}
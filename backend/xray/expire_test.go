@@ -0,0 +1,50 @@
+package xray
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestTrackExpiry(t *testing.T) {
+	x := &Xray{
+		expirations:  make(map[string]int64),
+		restrictions: make(map[restrictionKey]*common.RestrictedUser),
+		expireSignal: make(chan struct{}, 1),
+	}
+
+	now := time.Now().Unix()
+	expired := &common.User{Email: "expired@example.com", ExpireAt: now - 60}
+	soon := &common.User{Email: "soon@example.com", ExpireAt: now + 60}
+	later := &common.User{Email: "later@example.com", ExpireAt: now + 3600}
+
+	for _, user := range []*common.User{expired, soon, later} {
+		x.trackExpiry(user)
+	}
+
+	if !x.restricted(expired.Email) {
+		t.Fatal("expired user should be restricted")
+	}
+	if x.restricted(soon.Email) || x.restricted(later.Email) {
+		t.Fatal("users that haven't expired yet should not be restricted")
+	}
+
+	upcoming, _ := x.GetUpcomingExpirations(context.Background(), 600)
+	if len(upcoming.GetUsers()) != 1 || upcoming.GetUsers()[0].GetEmail() != soon.Email {
+		t.Fatalf("unexpected upcoming expirations: %v", upcoming.GetUsers())
+	}
+
+	upcoming, _ = x.GetUpcomingExpirations(context.Background(), 0)
+	if len(upcoming.GetUsers()) != 2 || upcoming.GetUsers()[1].GetEmail() != later.Email {
+		t.Fatalf("unexpected upcoming expirations: %v", upcoming.GetUsers())
+	}
+
+	// panel extends the subscription
+	expired.ExpireAt = now + 60
+	x.trackExpiry(expired)
+	if x.restricted(expired.Email) {
+		t.Fatal("user should be let back in after the expiry was extended")
+	}
+}
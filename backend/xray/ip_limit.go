@@ -0,0 +1,132 @@
+package xray
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+)
+
+const ipLimitCheckInterval = 15 * time.Second
+
+func (x *Xray) enforceIpLimits(baseCtx context.Context) {
+	ticker := time.NewTicker(ipLimitCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-baseCtx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(baseCtx, time.Second*10)
+			x.releaseIpLimits(ctx)
+			x.checkIpLimits(ctx)
+			cancel()
+		}
+	}
+}
+
+// limitsIps reports whether any tracked user has an ip limit.
+func (x *Xray) limitsIps() bool {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	for _, user := range x.users {
+		if user.GetMaxIps() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIpLimits removes users that are connected from more ips than their max_ips allows.
+func (x *Xray) checkIpLimits(ctx context.Context) {
+	x.restrictMu.Lock()
+	limits := make(map[string]uint32)
+	for email, user := range x.users {
+		if user.GetMaxIps() > 0 {
+			limits[email] = user.GetMaxIps()
+		}
+	}
+	x.restrictMu.Unlock()
+
+	cooldown := time.Duration(x.cfg.IpLimitCooldown) * time.Second
+
+	for email, maxIps := range limits {
+		if x.restricted(email) {
+			continue
+		}
+
+		stats, err := x.handler.GetUserOnlineIpListStats(ctx, email)
+		if err != nil {
+			// users without an active connection are not known to the stats service
+			continue
+		}
+
+		ips := stats.GetIps()
+		if len(ips) <= int(maxIps) {
+			continue
+		}
+
+		restricted := &common.RestrictedUser{
+			Email:     email,
+			Reason:    common.RestrictionReason_IpLimitExceeded,
+			Ips:       newestIps(ips, len(ips)-int(maxIps)),
+			ReleaseAt: time.Now().Add(cooldown).Unix(),
+		}
+		if !x.restrict(restricted) {
+			continue
+		}
+
+		log.Printf("user %s is connected from %d ips (limit %d), removing from inbounds for %s", email, len(ips), maxIps, cooldown)
+		x.cutOff(ctx, email)
+	}
+}
+
+// releaseIpLimits lets users back in once their cooldown is over.
+func (x *Xray) releaseIpLimits(ctx context.Context) {
+	now := time.Now().Unix()
+
+	x.restrictMu.Lock()
+	var released []string
+	for key, user := range x.restrictions {
+		if key.reason == common.RestrictionReason_IpLimitExceeded && user.GetReleaseAt() <= now {
+			released = append(released, key.email)
+		}
+	}
+	x.restrictMu.Unlock()
+
+	for _, email := range released {
+		x.lift(email, common.RestrictionReason_IpLimitExceeded)
+
+		user, ok := x.trackedUser(email)
+		if !ok || x.restricted(email) {
+			continue
+		}
+
+		log.Printf("ip limit cooldown of user %s is over, adding back to inbounds", email)
+		restarted, err := x.SyncUser(ctx, user)
+		if err != nil {
+			log.Printf("failed to add user %s back: %v", email, err)
+		}
+		if restarted {
+			x.notifyRestart("Enforcement", email)
+		}
+	}
+}
+
+// newestIps returns the count most recently seen ips, the ones that pushed the user over the limit.
+func newestIps(ips map[string]int64, count int) []string {
+	sorted := slices.SortedFunc(maps.Keys(ips), func(a, b string) int {
+		if c := cmp.Compare(ips[b], ips[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return sorted[:count]
+}
@@ -0,0 +1,50 @@
+package xray
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestNewestIps(t *testing.T) {
+	ips := map[string]int64{
+		"1.1.1.1": 100,
+		"2.2.2.2": 300,
+		"3.3.3.3": 200,
+	}
+
+	if newest := newestIps(ips, 2); !slices.Equal(newest, []string{"2.2.2.2", "3.3.3.3"}) {
+		t.Fatalf("unexpected newest ips: %v", newest)
+	}
+}
+
+func TestEnforcementEvents(t *testing.T) {
+	x := &Xray{
+		restrictions: make(map[restrictionKey]*common.RestrictedUser),
+	}
+
+	user := &common.RestrictedUser{
+		Email:  "ip@example.com",
+		Reason: common.RestrictionReason_IpLimitExceeded,
+		Ips:    []string{"2.2.2.2"},
+	}
+
+	if !x.restrict(user) {
+		t.Fatal("first restriction should be recorded")
+	}
+	if x.restrict(user) {
+		t.Fatal("duplicate restriction should be ignored")
+	}
+	x.lift(user.Email, common.RestrictionReason_IpLimitExceeded)
+	x.lift(user.Email, common.RestrictionReason_IpLimitExceeded)
+
+	events, _ := x.GetEnforcementEvents(context.Background(), 0)
+	if len(events.GetEvents()) != 2 {
+		t.Fatalf("expected 2 events, got %v", events.GetEvents())
+	}
+	if events.GetEvents()[0].GetReleased() || !events.GetEvents()[1].GetReleased() {
+		t.Fatalf("unexpected event order: %v", events.GetEvents())
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/Rexa/Gate/metrics"
 )
 
 func (x *Xray) checkXrayStatus() error {
@@ -67,6 +69,7 @@ func (x *Xray) checkXrayHealth(baseCtx context.Context) {
 			ctx, cancel := context.WithTimeout(baseCtx, time.Second*3)
 			_, err := x.GetSysStats(ctx)
 			cancel()
+			x.healthy.Store(err == nil)
 
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
@@ -74,6 +77,7 @@ func (x *Xray) checkXrayHealth(baseCtx context.Context) {
 				}
 
 				consecutiveFailures++
+				metrics.HealthCheckFailures.Inc()
 				// Only restart after multiple consecutive failures
 				if consecutiveFailures >= maxFailures {
 					log.Printf("xray health check failed %d times, restarting...", consecutiveFailures)
@@ -81,6 +85,7 @@ func (x *Xray) checkXrayHealth(baseCtx context.Context) {
 						log.Println(err.Error())
 					} else {
 						log.Println("xray restarted")
+						x.notifyRestart("HealthCheck")
 						consecutiveFailures = 0 // Reset counter after restart
 					}
 				}
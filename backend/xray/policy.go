@@ -0,0 +1,69 @@
+package xray
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/xtls/xray-core/infra/conf"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func policyToProto(level uint32, policy *conf.Policy) *common.Policy {
+	return &common.Policy{
+		Level:           level,
+		Handshake:       policy.Handshake,
+		ConnIdle:        policy.ConnectionIdle,
+		UplinkOnly:      policy.UplinkOnly,
+		DownlinkOnly:    policy.DownlinkOnly,
+		BufferSize:      policy.BufferSize,
+		StatsUserOnline: policy.StatsUserOnline,
+	}
+}
+
+// policyFromProto builds an xray level policy, user traffic stats are always kept on.
+func policyFromProto(policy *common.Policy) *conf.Policy {
+	return &conf.Policy{
+		Handshake:         policy.Handshake,
+		ConnectionIdle:    policy.ConnIdle,
+		UplinkOnly:        policy.UplinkOnly,
+		DownlinkOnly:      policy.DownlinkOnly,
+		BufferSize:        policy.BufferSize,
+		StatsUserUplink:   true,
+		StatsUserDownlink: true,
+		StatsUserOnline:   policy.GetStatsUserOnline(),
+	}
+}
+
+func (x *Xray) GetPolicies(_ context.Context) (*common.Policies, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	x.config.checkPolicy()
+
+	levels := slices.Sorted(maps.Keys(x.config.Policy.Levels))
+
+	policies := &common.Policies{Levels: make([]*common.Policy, 0, len(levels))}
+	for _, level := range levels {
+		policies.Levels = append(policies.Levels, policyToProto(level, x.config.Policy.Levels[level]))
+	}
+
+	return policies, nil
+}
+
+// SetPolicies creates or replaces the given policy levels, levels that are not listed are left as they are.
+// The core only loads policies on start, so it is restarted to apply them.
+func (x *Xray) SetPolicies(_ context.Context, policies *common.Policies) error {
+	x.mu.Lock()
+	x.config.checkPolicy()
+	for _, policy := range policies.GetLevels() {
+		x.config.Policy.Levels[policy.GetLevel()] = policyFromProto(policy)
+	}
+	if x.limitsIps() {
+		x.config.enableOnlineStats()
+	}
+	x.mu.Unlock()
+
+	return x.Restart()
+}
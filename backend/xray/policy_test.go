@@ -0,0 +1,59 @@
+package xray
+
+import (
+	"testing"
+
+	"github.com/xtls/xray-core/infra/conf"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestEnsureLevel(t *testing.T) {
+	c := &Config{Policy: &conf.PolicyConfig{Levels: map[uint32]*conf.Policy{
+		1: {StatsUserOnline: true},
+	}}}
+
+	if c.ensureLevel(1) {
+		t.Fatal("existing level should not be replaced")
+	}
+	if level := c.Policy.Levels[1]; !level.StatsUserUplink || !level.StatsUserDownlink || !level.StatsUserOnline {
+		t.Fatalf("unexpected stats flags on level 1: %+v", level)
+	}
+	if _, ok := c.Policy.Levels[0]; !ok {
+		t.Fatal("level 0 should always be defined")
+	}
+
+	if !c.ensureLevel(2) {
+		t.Fatal("missing level should be added")
+	}
+	if level := c.Policy.Levels[2]; !level.StatsUserUplink || !level.StatsUserDownlink {
+		t.Fatalf("user stats should be enabled on new levels: %+v", level)
+	}
+
+	c.enableOnlineStats()
+	for level, policy := range c.Policy.Levels {
+		if !policy.StatsUserOnline {
+			t.Fatalf("online stats should be enabled on level %d", level)
+		}
+	}
+}
+
+func TestEnsureOnlineStats(t *testing.T) {
+	c := &Config{}
+	c.ensureLevel(1)
+
+	if c.ensureOnlineStats(&common.User{Email: "unlimited@example.com"}) {
+		t.Fatal("users without an ip limit should not need online stats")
+	}
+	if !c.ensureOnlineStats(&common.User{Email: "unlimited@example.com"}, &common.User{Email: "limited@example.com", MaxIps: 2}) {
+		t.Fatal("a user with an ip limit should enable online stats")
+	}
+	for level, policy := range c.Policy.Levels {
+		if !policy.StatsUserOnline {
+			t.Fatalf("online stats should be enabled on level %d", level)
+		}
+	}
+	if c.ensureOnlineStats(&common.User{Email: "limited@example.com", MaxIps: 2}) {
+		t.Fatal("online stats that are already enabled should not restart the core")
+	}
+}
@@ -0,0 +1,143 @@
+package xray
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+)
+
+const quotaCheckInterval = 10 * time.Second
+
+type userQuota struct {
+	limit uint64
+	// base is the usage the panel reported on the last sync.
+	base uint64
+	// observed is the traffic counted by the node since base was set.
+	observed uint64
+	// counters keeps the last raw value of each user counter (uplink / downlink).
+	counters map[string]int64
+}
+
+func (q *userQuota) used() uint64 {
+	return q.base + q.observed
+}
+
+func (q *userQuota) exceeded() bool {
+	return q.limit > 0 && q.used() >= q.limit
+}
+
+// trackQuota registers the data limit of a synced user.
+// A user that was cut off is let back in when the panel raises the limit or resets the usage.
+func (x *Xray) trackQuota(user *common.User) {
+	email := user.GetEmail()
+
+	x.restrictMu.Lock()
+	quota, ok := x.quotas[email]
+	if !ok {
+		quota = &userQuota{counters: make(map[string]int64)}
+		x.quotas[email] = quota
+	}
+	if !ok || user.GetUsedTraffic() != quota.base {
+		quota.base = user.GetUsedTraffic()
+		quota.observed = 0
+	}
+	quota.limit = user.GetDataLimit()
+	exceeded := quota.exceeded()
+	used := quota.used()
+	x.restrictMu.Unlock()
+
+	if !exceeded {
+		x.lift(email, common.RestrictionReason_DataLimitReached)
+		return
+	}
+
+	x.restrict(&common.RestrictedUser{
+		Email:       email,
+		Reason:      common.RestrictionReason_DataLimitReached,
+		UsedTraffic: used,
+		DataLimit:   user.GetDataLimit(),
+	})
+}
+
+func (x *Xray) untrackQuota(email string) {
+	x.restrictMu.Lock()
+	delete(x.quotas, email)
+	x.restrictMu.Unlock()
+
+	x.lift(email, common.RestrictionReason_DataLimitReached)
+}
+
+func (x *Xray) enforceQuotas(baseCtx context.Context) {
+	ticker := time.NewTicker(quotaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-baseCtx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(baseCtx, time.Second*5)
+			if err := x.checkQuotas(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Println("failed to check user quotas:", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// checkQuotas reads the user counters without resetting them, so the panel keeps its own view.
+// When the panel resets a counter, the new raw value is taken as the traffic since the reset.
+func (x *Xray) checkQuotas(ctx context.Context) error {
+	stats, err := x.handler.GetUsersStats(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	var exceeded []*common.RestrictedUser
+
+	x.restrictMu.Lock()
+	for _, stat := range stats.GetStats() {
+		quota, ok := x.quotas[stat.GetName()]
+		if !ok {
+			continue
+		}
+
+		value := stat.GetValue()
+		last, seen := quota.counters[stat.GetType()]
+		quota.counters[stat.GetType()] = value
+
+		switch {
+		case !seen:
+			continue
+		case value >= last:
+			quota.observed += uint64(value - last)
+		default:
+			quota.observed += uint64(value)
+		}
+	}
+
+	for email, quota := range x.quotas {
+		if quota.exceeded() {
+			exceeded = append(exceeded, &common.RestrictedUser{
+				Email:       email,
+				Reason:      common.RestrictionReason_DataLimitReached,
+				UsedTraffic: quota.used(),
+				DataLimit:   quota.limit,
+			})
+		}
+	}
+	x.restrictMu.Unlock()
+
+	for _, user := range exceeded {
+		if !x.restrict(user) {
+			continue
+		}
+		log.Printf("user %s reached the data limit (%d of %d bytes), removing from inbounds", user.GetEmail(), user.GetUsedTraffic(), user.GetDataLimit())
+		x.cutOff(ctx, user.GetEmail())
+	}
+
+	return nil
+}
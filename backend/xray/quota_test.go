@@ -0,0 +1,53 @@
+package xray
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestTrackQuota(t *testing.T) {
+	x := &Xray{
+		quotas:       make(map[string]*userQuota),
+		restrictions: make(map[restrictionKey]*common.RestrictedUser),
+	}
+
+	user := &common.User{Email: "quota@example.com", DataLimit: 1000, UsedTraffic: 400}
+	x.trackQuota(user)
+	if x.restricted(user.Email) {
+		t.Fatal("user under the data limit should not be restricted")
+	}
+
+	// traffic counted by the node pushes the user over the limit
+	x.quotas[user.Email].observed = 600
+	x.trackQuota(user)
+	if !x.restricted(user.Email) {
+		t.Fatal("user over the data limit should be restricted")
+	}
+
+	// panel raises the limit
+	user.DataLimit = 2000
+	x.trackQuota(user)
+	if x.restricted(user.Email) {
+		t.Fatal("user should be let back in after the limit was raised")
+	}
+
+	// panel resets usage of a user that went over the limit again
+	x.quotas[user.Email].observed = 1600
+	x.trackQuota(user)
+	if !x.restricted(user.Email) {
+		t.Fatal("user over the raised data limit should be restricted")
+	}
+
+	user.UsedTraffic = 0
+	x.trackQuota(user)
+	if x.restricted(user.Email) {
+		t.Fatal("user should be let back in after usage was reset")
+	}
+
+	restricted, _ := x.GetRestrictedUsers(context.Background())
+	if len(restricted.GetUsers()) != 0 {
+		t.Fatalf("expected no restricted users, got %v", restricted.GetUsers())
+	}
+}
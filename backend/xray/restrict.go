@@ -0,0 +1,210 @@
+package xray
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Rexa/Gate/common"
+)
+
+// maxEnforcementEvents caps the number of enforcement events kept in memory.
+const maxEnforcementEvents = 1000
+
+type restrictionKey struct {
+	email  string
+	reason common.RestrictionReason
+}
+
+// recordEvent must be called with restrictMu held.
+func (x *Xray) recordEvent(event *common.EnforcementEvent) {
+	event.Time = time.Now().Unix()
+	x.events = append(x.events, event)
+	if len(x.events) > maxEnforcementEvents {
+		x.events = slices.Clone(x.events[len(x.events)-maxEnforcementEvents:])
+	}
+}
+
+// restrict records that the user is cut off for the given reason.
+// It returns false if the same restriction was already in place.
+func (x *Xray) restrict(user *common.RestrictedUser) bool {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	key := restrictionKey{email: user.GetEmail(), reason: user.GetReason()}
+	if _, ok := x.restrictions[key]; ok {
+		return false
+	}
+	if user.RestrictedAt == 0 {
+		user.RestrictedAt = time.Now().Unix()
+	}
+	x.restrictions[key] = user
+	x.recordEvent(&common.EnforcementEvent{Email: user.GetEmail(), Reason: user.GetReason(), Ips: user.GetIps()})
+	return true
+}
+
+// lift drops a single restriction, the user stays cut off if any other one is left.
+func (x *Xray) lift(email string, reason common.RestrictionReason) {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	key := restrictionKey{email: email, reason: reason}
+	if _, ok := x.restrictions[key]; !ok {
+		return
+	}
+	delete(x.restrictions, key)
+	x.recordEvent(&common.EnforcementEvent{Email: email, Reason: reason, Released: true})
+}
+
+func (x *Xray) forgetRestrictions(email string) {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	for key := range x.restrictions {
+		if key.email == email {
+			delete(x.restrictions, key)
+		}
+	}
+}
+
+func (x *Xray) restricted(email string) bool {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	for key := range x.restrictions {
+		if key.email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// trackUser updates the node-side limits of a synced user.
+func (x *Xray) trackUser(user *common.User) {
+	x.restrictMu.Lock()
+	x.users[user.GetEmail()] = user
+	x.restrictMu.Unlock()
+
+	x.trackQuota(user)
+	x.trackExpiry(user)
+}
+
+func (x *Xray) untrackUser(email string) {
+	x.restrictMu.Lock()
+	delete(x.users, email)
+	x.restrictMu.Unlock()
+
+	x.untrackQuota(email)
+	x.untrackExpiry(email)
+	x.lift(email, common.RestrictionReason_IpLimitExceeded)
+}
+
+// trackedUser returns the last definition of the user synced by the panel.
+func (x *Xray) trackedUser(email string) (*common.User, bool) {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	user, ok := x.users[email]
+	return user, ok
+}
+
+// syncTracked replaces the tracked limits with the ones of a full user sync.
+func (x *Xray) syncTracked(users []*common.User) {
+	synced := make(map[string]bool, len(users))
+	for _, user := range users {
+		synced[user.GetEmail()] = true
+		x.trackUser(user)
+	}
+
+	x.restrictMu.Lock()
+	var stale []string
+	for email := range x.users {
+		if !synced[email] {
+			stale = append(stale, email)
+		}
+	}
+	x.restrictMu.Unlock()
+
+	for _, email := range stale {
+		x.untrackUser(email)
+	}
+}
+
+// activeUsers returns the users that are allowed to be loaded into the core.
+func (x *Xray) activeUsers(users []*common.User) []*common.User {
+	active := make([]*common.User, 0, len(users))
+	for _, user := range users {
+		if !x.restricted(user.GetEmail()) {
+			active = append(active, user)
+		}
+	}
+	return active
+}
+
+// cutOff removes the user from every inbound of the running core and from the cached config,
+// so a restart doesn't bring them back. Inbounds that can't drop users live are reloaded with a restart.
+func (x *Xray) cutOff(ctx context.Context, email string) {
+	var restart bool
+	for _, inbound := range x.config.InboundConfigs {
+		if inbound.exclude {
+			continue
+		}
+		if _, ok := inbound.clients()[email]; !ok {
+			continue
+		}
+
+		inbound.removeUser(email)
+		if !inbound.managesUsers() {
+			restart = true
+			continue
+		}
+		if err := x.handler.RemoveInboundUser(ctx, inbound.Tag, email); err != nil {
+			log.Printf("failed to remove user %s from inbound %s: %v", email, inbound.Tag, err)
+		}
+	}
+
+	if restart {
+		if err := x.Restart(); err != nil {
+			log.Printf("failed to restart core to remove user %s: %v", email, err)
+			return
+		}
+		x.notifyRestart("Enforcement", email)
+	}
+}
+
+func (x *Xray) GetRestrictedUsers(_ context.Context) (*common.RestrictedUsersResponse, error) {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	response := &common.RestrictedUsersResponse{Users: make([]*common.RestrictedUser, 0, len(x.restrictions))}
+	for _, user := range x.restrictions {
+		response.Users = append(response.Users, proto.Clone(user).(*common.RestrictedUser))
+	}
+
+	slices.SortFunc(response.Users, func(a, b *common.RestrictedUser) int {
+		if c := strings.Compare(a.GetEmail(), b.GetEmail()); c != 0 {
+			return c
+		}
+		return int(a.GetReason()) - int(b.GetReason())
+	})
+
+	return response, nil
+}
+
+func (x *Xray) GetEnforcementEvents(_ context.Context, since int64) (*common.EnforcementEventsResponse, error) {
+	x.restrictMu.Lock()
+	defer x.restrictMu.Unlock()
+
+	response := &common.EnforcementEventsResponse{}
+	for _, event := range x.events {
+		if event.GetTime() >= since {
+			response.Events = append(response.Events, proto.Clone(event).(*common.EnforcementEvent))
+		}
+	}
+
+	return response, nil
+}
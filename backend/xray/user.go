@@ -3,10 +3,16 @@ package xray
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"maps"
+	"reflect"
 	"slices"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/Rexa/Gate/backend/xray/api"
 	"github.com/Rexa/Gate/common"
 )
@@ -34,6 +40,20 @@ func setupUserAccount(user *common.User) (api.ProxySettings, error) {
 		settings.Shadowsocks2022 = api.NewShadowsocksAccount(user)
 	}
 
+	if user.GetProxies().GetSocks() != nil {
+		settings.Socks = api.NewSocksAccount(user)
+	}
+
+	if user.GetProxies().GetHttp() != nil {
+		settings.Http = api.NewHttpAccount(user)
+	}
+
+	if user.GetProxies().GetWireguard() != nil {
+		if wireguardAccount, err := api.NewWireguardAccount(user); err == nil {
+			settings.Wireguard = wireguardAccount
+		}
+	}
+
 	return settings, nil
 }
 
@@ -120,15 +140,34 @@ func isActiveInbound(inbound *Inbound, inbounds []string, settings api.ProxySett
 				return nil, false
 			}
 			return settings.Shadowsocks, true
+
+		case Socks:
+			if settings.Socks == nil {
+				return nil, false
+			}
+			return settings.Socks, true
+
+		case Http:
+			if settings.Http == nil {
+				return nil, false
+			}
+			return settings.Http, true
+
+		case Wireguard:
+			if settings.Wireguard == nil {
+				return nil, false
+			}
+			return settings.Wireguard, true
 		}
 	}
 	return nil, false
 }
 
-func (x *Xray) SyncUser(ctx context.Context, user *common.User) error {
+// SyncUser applies a single user, it reports whether the core had to be restarted for it.
+func (x *Xray) SyncUser(ctx context.Context, user *common.User) (bool, error) {
 	proxySetting, err := setupUserAccount(user)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	handler := x.handler
@@ -137,14 +176,37 @@ func (x *Xray) SyncUser(ctx context.Context, user *common.User) error {
 	var errMessage string
 
 	userInbounds := user.GetInbounds()
+	if len(userInbounds) == 0 {
+		x.untrackUser(user.GetEmail())
+	} else {
+		x.trackUser(user)
+	}
+	if x.restricted(user.GetEmail()) {
+		userInbounds = nil
+	}
+
+	var restart bool
 
 	for _, inbound := range inbounds {
 		if inbound.exclude {
 			continue
 		}
 
-		_ = handler.RemoveInboundUser(ctx, inbound.Tag, user.Email)
 		account, isActive := isActiveInbound(inbound, userInbounds, proxySetting)
+		if !inbound.managesUsers() {
+			current, loaded := inbound.clients()[user.GetEmail()]
+			switch {
+			case isActive && !reflect.DeepEqual(current, account):
+				inbound.updateUser(account)
+				restart = true
+			case !isActive && loaded:
+				inbound.removeUser(user.GetEmail())
+				restart = true
+			}
+			continue
+		}
+
+		_ = handler.RemoveInboundUser(ctx, inbound.Tag, user.Email)
 		if isActive {
 			inbound.updateUser(account)
 			err = handler.AddInboundUser(ctx, inbound.Tag, account)
@@ -157,16 +219,323 @@ func (x *Xray) SyncUser(ctx context.Context, user *common.User) error {
 		}
 	}
 
+	// the core only loads policies and socks / http / wireguard accounts on start
+	if len(userInbounds) > 0 && x.config.ensureLevel(user.GetLevel()) {
+		restart = true
+	}
+	if x.config.ensureOnlineStats(user) {
+		restart = true
+	}
+	if restart {
+		if err = x.Restart(); err != nil {
+			return false, err
+		}
+	}
+
 	if errMessage != "" {
-		return errors.New("failed to add user:" + errMessage)
+		return restart, errors.New("failed to add user:" + errMessage)
 	}
-	return nil
+	return restart, nil
 }
 
-func (x *Xray) SyncUsers(_ context.Context, users []*common.User) error {
-	x.config.syncUsers(users)
-	if err := x.Restart(); err != nil {
-		return err
+// diffClients compares the accounts an inbound currently holds with the desired ones
+// and returns the emails that have to be added, removed or replaced.
+func diffClients(current, desired map[string]api.Account) (added, removed, updated []string) {
+	for email, account := range desired {
+		old, ok := current[email]
+		switch {
+		case !ok:
+			added = append(added, email)
+		case !reflect.DeepEqual(old, account):
+			updated = append(updated, email)
+		}
 	}
-	return nil
+
+	for email := range current {
+		if _, ok := desired[email]; !ok {
+			removed = append(removed, email)
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(removed)
+	slices.Sort(updated)
+	return added, removed, updated
+}
+
+// SyncUsers applies the given user set as a diff against the clients already loaded
+// on each inbound. Changes go through the handler api, the core is only restarted
+// when the api fails to apply them.
+func (x *Xray) SyncUsers(ctx context.Context, users []*common.User) (*common.SyncUsersResponse, error) {
+	handler := x.handler
+
+	proxySettings := make(map[string]api.ProxySettings, len(users))
+	for _, user := range users {
+		settings, err := setupUserAccount(user)
+		if err != nil {
+			return nil, err
+		}
+		proxySettings[user.GetEmail()] = settings
+	}
+
+	x.syncTracked(users)
+
+	var newLevels, restartAccounts bool
+	for _, user := range users {
+		if x.config.ensureLevel(user.GetLevel()) {
+			newLevels = true
+		}
+	}
+	onlineStats := x.config.ensureOnlineStats(users...)
+
+	before := make(map[string]bool)
+	after := make(map[string]bool)
+	changed := make(map[string]bool)
+
+	var apiErr error
+	apply := func(err error) {
+		if err != nil && apiErr == nil {
+			apiErr = err
+		}
+	}
+
+	for _, inbound := range x.config.InboundConfigs {
+		if inbound.exclude {
+			continue
+		}
+
+		current := inbound.clients()
+		desired := make(map[string]api.Account, len(users))
+		accounts := make([]api.Account, 0, len(users))
+
+		for _, user := range users {
+			email := user.GetEmail()
+			if _, ok := desired[email]; ok || x.restricted(email) {
+				continue
+			}
+			account, isActive := isActiveInbound(inbound, user.GetInbounds(), proxySettings[email])
+			if !isActive {
+				continue
+			}
+			desired[email] = account
+			accounts = append(accounts, account)
+		}
+
+		for email := range current {
+			before[email] = true
+		}
+		for email := range desired {
+			after[email] = true
+		}
+
+		added, removed, updated := diffClients(current, desired)
+
+		if !inbound.managesUsers() {
+			for _, email := range slices.Concat(added, removed, updated) {
+				changed[email] = true
+				restartAccounts = true
+			}
+			inbound.setClients(accounts)
+			continue
+		}
+
+		for _, email := range removed {
+			changed[email] = true
+			if apiErr == nil {
+				err := common.InterceptNotFound(handler.RemoveInboundUser(ctx, inbound.Tag, email))
+				if status.Code(err) != codes.NotFound {
+					apply(err)
+				}
+			}
+		}
+
+		for _, email := range updated {
+			changed[email] = true
+			if apiErr == nil {
+				_ = handler.RemoveInboundUser(ctx, inbound.Tag, email)
+				apply(handler.AddInboundUser(ctx, inbound.Tag, desired[email]))
+			}
+		}
+
+		for _, email := range added {
+			changed[email] = true
+			if apiErr == nil {
+				apply(handler.AddInboundUser(ctx, inbound.Tag, desired[email]))
+			}
+		}
+
+		inbound.setClients(accounts)
+	}
+
+	emails := make(map[string]bool, len(users))
+	for _, user := range users {
+		emails[user.GetEmail()] = true
+	}
+	for email := range before {
+		emails[email] = true
+	}
+
+	response := &common.SyncUsersResponse{}
+	for email := range emails {
+		switch {
+		case !before[email] && after[email]:
+			response.Added++
+		case before[email] && !after[email]:
+			response.Removed++
+		case changed[email]:
+			response.Updated++
+		default:
+			response.Unchanged++
+		}
+	}
+
+	switch {
+	case apiErr != nil:
+		log.Println("failed to apply users through xray api, restarting core:", apiErr)
+	case newLevels:
+		log.Println("users reference new policy levels, restarting core")
+	case onlineStats:
+		log.Println("users have ip limits, restarting core to enable online stats")
+	case restartAccounts:
+		log.Println("socks / http / wireguard accounts changed, restarting core")
+	}
+	if apiErr != nil || newLevels || onlineStats || restartAccounts {
+		if err := x.Restart(); err != nil {
+			return nil, err
+		}
+		response.Restarted = true
+	}
+
+	return response, nil
+}
+
+// RemoveUsers drops the given users from every managed inbound and stops tracking their limits.
+// Emails the node has never seen are reported back as unknown.
+func (x *Xray) RemoveUsers(ctx context.Context, emails []string) (*common.RemoveUsersResponse, error) {
+	handler := x.handler
+
+	var apiErr error
+	var restart bool
+
+	response := &common.RemoveUsersResponse{}
+	for _, email := range slices.Compact(slices.Sorted(slices.Values(emails))) {
+		_, known := x.trackedUser(email)
+		x.untrackUser(email)
+
+		for _, inbound := range x.config.InboundConfigs {
+			if inbound.exclude {
+				continue
+			}
+			if _, ok := inbound.clients()[email]; !ok {
+				continue
+			}
+			known = true
+
+			inbound.removeUser(email)
+			if !inbound.managesUsers() {
+				restart = true
+				continue
+			}
+			if apiErr == nil {
+				err := common.InterceptNotFound(handler.RemoveInboundUser(ctx, inbound.Tag, email))
+				if status.Code(err) != codes.NotFound {
+					apiErr = err
+				}
+			}
+		}
+
+		if known {
+			response.Removed = append(response.Removed, email)
+		} else {
+			response.Unknown = append(response.Unknown, email)
+		}
+	}
+
+	switch {
+	case apiErr != nil:
+		log.Println("failed to remove users through xray api, restarting core:", apiErr)
+	case restart:
+		log.Println("socks / http / wireguard accounts changed, restarting core")
+	}
+	if apiErr != nil || restart {
+		if err := x.Restart(); err != nil {
+			return nil, err
+		}
+		response.Restarted = true
+	}
+
+	return response, nil
+}
+
+// normalizations describes how a loaded account differs from the user the panel synced.
+func normalizations(account api.Account, user *common.User) []string {
+	if user == nil {
+		return nil
+	}
+
+	var notes []string
+	switch account := account.(type) {
+	case *api.VlessAccount:
+		if flow := user.GetProxies().GetVless().GetFlow(); flow != account.Flow {
+			notes = append(notes, fmt.Sprintf("vless flow %q stripped, not supported by the inbound stream settings", flow))
+		}
+	case *api.ShadowsocksAccount:
+		if account.Password != user.GetProxies().GetShadowsocks().GetPassword() {
+			notes = append(notes, "shadowsocks password re-derived to a base64 key for the inbound method")
+		}
+	case *api.ShadowsocksTcpAccount:
+		if method := user.GetProxies().GetShadowsocks().GetMethod(); method != account.Method {
+			notes = append(notes, fmt.Sprintf("shadowsocks method %q not supported, using %q", method, account.Method))
+		}
+	}
+	return notes
+}
+
+// loadedUsers collects the users held in the client lists of the managed inbounds.
+func (x *Xray) loadedUsers() map[string]*common.LoadedUser {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	users := make(map[string]*common.LoadedUser)
+	for _, inbound := range x.config.InboundConfigs {
+		if inbound.exclude {
+			continue
+		}
+
+		for email, account := range inbound.clients() {
+			user, ok := users[email]
+			if !ok {
+				user = &common.LoadedUser{Email: email, Level: account.GetLevel()}
+				users[email] = user
+			}
+
+			tracked, _ := x.trackedUser(email)
+			user.Inbounds = append(user.Inbounds, &common.UserInbound{
+				Tag:            inbound.Tag,
+				Protocol:       inbound.Protocol,
+				Normalizations: normalizations(account, tracked),
+			})
+		}
+	}
+	return users
+}
+
+func (x *Xray) ListUsers(_ context.Context) (*common.LoadedUsersResponse, error) {
+	users := x.loadedUsers()
+
+	response := &common.LoadedUsersResponse{Users: make([]*common.LoadedUser, 0, len(users))}
+	for _, email := range slices.Sorted(maps.Keys(users)) {
+		response.Users = append(response.Users, users[email])
+	}
+
+	return response, nil
+}
+
+func (x *Xray) GetUser(_ context.Context, email string) (*common.LoadedUser, error) {
+	user, ok := x.loadedUsers()[email]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %s is not loaded on any inbound", email)
+	}
+
+	return user, nil
 }
@@ -0,0 +1,175 @@
+package xray
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/Rexa/Gate/backend/xray/api"
+	"github.com/Rexa/Gate/common"
+)
+
+func TestDiffClients(t *testing.T) {
+	id := uuid.New()
+
+	current := map[string]api.Account{
+		"kept@example.com":    &api.VmessAccount{BaseAccount: api.BaseAccount{Email: "kept@example.com"}, ID: id},
+		"changed@example.com": &api.TrojanAccount{BaseAccount: api.BaseAccount{Email: "changed@example.com"}, Password: "old"},
+		"gone@example.com":    &api.TrojanAccount{BaseAccount: api.BaseAccount{Email: "gone@example.com"}, Password: "gone"},
+	}
+
+	desired := map[string]api.Account{
+		"kept@example.com":    &api.VmessAccount{BaseAccount: api.BaseAccount{Email: "kept@example.com"}, ID: id},
+		"changed@example.com": &api.TrojanAccount{BaseAccount: api.BaseAccount{Email: "changed@example.com"}, Password: "new"},
+		"new@example.com":     &api.TrojanAccount{BaseAccount: api.BaseAccount{Email: "new@example.com"}, Password: "new"},
+	}
+
+	added, removed, updated := diffClients(current, desired)
+
+	if !slices.Equal(added, []string{"new@example.com"}) {
+		t.Errorf("unexpected added users: %v", added)
+	}
+	if !slices.Equal(removed, []string{"gone@example.com"}) {
+		t.Errorf("unexpected removed users: %v", removed)
+	}
+	if !slices.Equal(updated, []string{"changed@example.com"}) {
+		t.Errorf("unexpected updated users: %v", updated)
+	}
+}
+
+func TestNormalizations(t *testing.T) {
+	user := &common.User{
+		Email: "normalized@example.com",
+		Proxies: &common.Proxy{
+			Vless:       &common.Vless{Id: uuid.NewString(), Flow: "xtls-rprx-vision"},
+			Shadowsocks: &common.Shadowsocks{Password: "plain", Method: "rc4-md5"},
+		},
+	}
+
+	inbound := &Inbound{StreamSettings: map[string]interface{}{"network": "ws"}}
+	vless, err := api.NewVlessAccount(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped := checkVless(inbound, *vless)
+	if notes := normalizations(&stripped, user); len(notes) != 1 {
+		t.Errorf("expected the stripped flow to be reported, got %v", notes)
+	}
+
+	ss2022 := checkShadowsocks2022("2022-blake3-aes-256-gcm", *api.NewShadowsocksAccount(user))
+	if notes := normalizations(&ss2022, user); len(notes) != 1 {
+		t.Errorf("expected the re-derived password to be reported, got %v", notes)
+	}
+
+	if notes := normalizations(api.NewShadowsocksTcpAccount(user), user); len(notes) != 1 {
+		t.Errorf("expected the unsupported method to be reported, got %v", notes)
+	}
+
+	if notes := normalizations(api.NewTrojanAccount(user), user); len(notes) != 0 {
+		t.Errorf("unexpected normalizations for trojan: %v", notes)
+	}
+}
+
+func TestSocksAccounts(t *testing.T) {
+	inbound := &Inbound{Tag: "socks-in", Protocol: Socks, Settings: map[string]interface{}{"auth": "password"}}
+	user := &common.User{
+		Email:    "socks@example.com",
+		Proxies:  &common.Proxy{Socks: &common.Socks{Password: "secret"}},
+		Inbounds: []string{"socks-in"},
+	}
+
+	inbound.syncUsers([]*common.User{user})
+	if _, ok := inbound.clients()[user.Email]; !ok {
+		t.Fatal("socks account was not added")
+	}
+
+	raw, err := json.Marshal(inbound.Settings["accounts"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accounts []map[string]interface{}
+	if err = json.Unmarshal(raw, &accounts); err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0]["user"] != user.Email || accounts[0]["pass"] != "secret" {
+		t.Errorf("unexpected socks accounts: %s", raw)
+	}
+
+	inbound.removeUser(user.Email)
+	if len(inbound.clients()) != 0 {
+		t.Error("socks account was not removed")
+	}
+}
+
+func TestSocksPasswordAuth(t *testing.T) {
+	inbound := &Inbound{Tag: "socks-in", Protocol: Socks, Settings: map[string]interface{}{"auth": "noauth"}}
+	user := &common.User{
+		Email:    "socks@example.com",
+		Proxies:  &common.Proxy{Socks: &common.Socks{Password: "secret"}},
+		Inbounds: []string{"socks-in"},
+	}
+
+	inbound.syncUsers(nil)
+	if inbound.Settings["auth"] != "noauth" {
+		t.Errorf("auth changed without accounts: %v", inbound.Settings["auth"])
+	}
+
+	inbound.syncUsers([]*common.User{user})
+	if inbound.Settings["auth"] != "password" {
+		t.Errorf("expected password auth after syncing accounts, got %v", inbound.Settings["auth"])
+	}
+
+	inbound = &Inbound{Tag: "socks-in", Protocol: Socks, Settings: map[string]interface{}{}}
+	inbound.updateUser(api.NewSocksAccount(user))
+	if inbound.Settings["auth"] != "password" {
+		t.Errorf("expected password auth after adding an account, got %v", inbound.Settings["auth"])
+	}
+
+	inbound = &Inbound{Tag: "socks-in", Protocol: Socks, Settings: map[string]interface{}{"auth": "noauth"}}
+	inbound.setClients(nil)
+	if inbound.Settings["auth"] != "noauth" {
+		t.Errorf("auth changed by an empty user sync: %v", inbound.Settings["auth"])
+	}
+	inbound.setClients([]api.Account{api.NewSocksAccount(user)})
+	if inbound.Settings["auth"] != "password" {
+		t.Errorf("expected password auth after a user sync, got %v", inbound.Settings["auth"])
+	}
+}
+
+func TestWireguardPeers(t *testing.T) {
+	inbound := &Inbound{Tag: "wg-in", Protocol: Wireguard, Settings: map[string]interface{}{}}
+	peer := &common.User{
+		Email: "peer@example.com",
+		Proxies: &common.Proxy{Wireguard: &common.Wireguard{
+			PublicKey:  "Pf9gQ5xT7JQZpRk8v5n1yH3x0uX6Yt2wB9cLmN4oK0s=",
+			AllowedIps: []string{"10.0.0.2/32"},
+		}},
+		Inbounds: []string{"wg-in"},
+	}
+	invalid := &common.User{
+		Email:    "invalid@example.com",
+		Proxies:  &common.Proxy{Wireguard: &common.Wireguard{PublicKey: "not a key"}},
+		Inbounds: []string{"wg-in"},
+	}
+
+	inbound.syncUsers([]*common.User{peer, invalid})
+	clients := inbound.clients()
+	if _, ok := clients[peer.Email]; !ok || len(clients) != 1 {
+		t.Fatalf("unexpected wireguard peers: %v", clients)
+	}
+
+	if _, err := clients[peer.Email].Message(); err != nil {
+		t.Errorf("failed to build peer message: %v", err)
+	}
+
+	account, isActive := isActiveInbound(inbound, peer.Inbounds, api.ProxySettings{Wireguard: clients[peer.Email].(*api.WireguardAccount)})
+	if !isActive || inbound.managesUsers() {
+		t.Fatal("wireguard inbounds should be active and reloaded with a restart")
+	}
+	inbound.removeUser(account.GetEmail())
+	if len(inbound.clients()) != 0 {
+		t.Error("wireguard peer was not removed")
+	}
+}
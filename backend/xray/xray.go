@@ -6,20 +6,33 @@ import (
 	"log"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Rexa/Gate/backend"
 	"github.com/Rexa/Gate/backend/xray/api"
 	"github.com/Rexa/Gate/common"
 	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/metrics"
 )
 
 type Xray struct {
-	config     *Config
-	cfg        *config.Config
-	core       *Core
-	handler    *api.XrayHandler
+	config       *Config
+	cfg          *config.Config
+	core         *Core
+	handler      *api.XrayHandler
+	users        map[string]*common.User
+	quotas       map[string]*userQuota
+	expirations  map[string]int64
+	restrictions map[restrictionKey]*common.RestrictedUser
+	events       []*common.EnforcementEvent
+	expireSignal chan struct{}
+	// onRestart is told about restarts the node decides on by itself.
+	onRestart backend.RestartHook
+	// healthy is the result of the last health check.
+	healthy    atomic.Bool
 	cancelFunc context.CancelFunc
+	restrictMu sync.Mutex
 	mu         sync.RWMutex
 }
 
@@ -42,10 +55,17 @@ func NewXray(ctx context.Context, port int, cfg *config.Config) (*Xray, error) {
 	xCtx, xCancel := context.WithCancel(context.Background())
 
 	xray := &Xray{
-		cancelFunc: xCancel,
-		cfg:        cfg,
+		cancelFunc:   xCancel,
+		cfg:          cfg,
+		users:        make(map[string]*common.User),
+		quotas:       make(map[string]*userQuota),
+		expirations:  make(map[string]int64),
+		restrictions: make(map[restrictionKey]*common.RestrictedUser),
+		expireSignal: make(chan struct{}, 1),
 	}
 
+	xray.onRestart, _ = ctx.Value(backend.RestartHookKey{}).(backend.RestartHook)
+
 	start := time.Now()
 
 	xrayConfig, ok := ctx.Value(backend.ConfigKey{}).(*Config)
@@ -58,7 +78,14 @@ func NewXray(ctx context.Context, port int, cfg *config.Config) (*Xray, error) {
 	}
 
 	users := ctx.Value(backend.UsersKey{}).([]*common.User)
-	xrayConfig.syncUsers(users)
+	for _, user := range users {
+		xray.trackUser(user)
+		xrayConfig.ensureLevel(user.GetLevel())
+		if user.GetMaxIps() > 0 {
+			xrayConfig.enableOnlineStats()
+		}
+	}
+	xrayConfig.syncUsers(xray.activeUsers(users))
 
 	xray.config = xrayConfig
 
@@ -86,6 +113,7 @@ func NewXray(ctx context.Context, port int, cfg *config.Config) (*Xray, error) {
 		return nil, err
 	}
 	xray.handler = handler
+	xray.healthy.Store(true)
 
 	// Wait a bit for Xray to fully initialize before starting health checks
 	// This prevents false positives during startup
@@ -94,6 +122,10 @@ func NewXray(ctx context.Context, port int, cfg *config.Config) (*Xray, error) {
 		xray.checkXrayHealth(xCtx)
 	}()
 
+	go xray.enforceQuotas(xCtx)
+	go xray.scheduleExpirations(xCtx)
+	go xray.enforceIpLimits(xCtx)
+
 	log.Println("xray started, Version:", xray.Version())
 
 	return xray, nil
@@ -117,15 +149,27 @@ func (x *Xray) Started() bool {
 	return x.core.Started()
 }
 
+func (x *Xray) Healthy() bool {
+	return x.healthy.Load()
+}
+
 func (x *Xray) Restart() error {
 	x.mu.Lock()
 	defer x.mu.Unlock()
 	if err := x.core.Restart(x.config, x.cfg.Debug); err != nil {
 		return err
 	}
+	metrics.CoreRestarts.Inc()
 	return nil
 }
 
+// notifyRestart reports a restart that wasn't requested by the panel.
+func (x *Xray) notifyRestart(reason string, users ...string) {
+	if x.onRestart != nil {
+		x.onRestart(reason, users)
+	}
+}
+
 func (x *Xray) Shutdown() {
 	x.mu.Lock()
 	defer x.mu.Unlock()
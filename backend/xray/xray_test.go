@@ -124,7 +124,7 @@ func TestXrayBackend(t *testing.T) {
 			stat.GetName(), stat.GetValue(), stat.GetType(), stat.GetLink())
 	}
 
-	if err = back.SyncUser(ctx1, user2); err != nil {
+	if _, err = back.SyncUser(ctx1, user2); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1,41 +1,200 @@
 package common
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-func ReadProtoBody(body io.ReadCloser, message proto.Message) error {
-	data, err := io.ReadAll(body)
+const (
+	ContentTypeProto = "application/x-protobuf"
+	ContentTypeJSON  = "application/json"
+)
+
+var jsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// ReadProtoBody decodes the request body as protobuf, or as protojson when the Content-Type is application/json.
+func ReadProtoBody(r *http.Request, message proto.Message) error {
+	data, err := io.ReadAll(r.Body)
 	if err != nil {
 		return err
 	}
-	defer body.Close()
+	defer r.Body.Close()
 
-	// Decode into a map
-	if err = proto.Unmarshal(data, message); err != nil {
-		return err
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return proto.Unmarshal(data, message)
+	}
+	// an empty body is a valid empty message in protobuf but not in json
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return jsonUnmarshal.Unmarshal(data, message)
+}
+
+// ReadProtoQuery fills message from query parameters named after its fields, repeated fields take the
+// parameter more than once. Only scalar and enum fields can be set, enums by name or number.
+func ReadProtoQuery(query url.Values, message proto.Message) error {
+	m := message.ProtoReflect()
+	fields := m.Descriptor().Fields()
+
+	for key, values := range query {
+		fd := fields.ByName(protoreflect.Name(key))
+		if fd == nil {
+			fd = fields.ByJSONName(key)
+		}
+		if fd == nil {
+			return fmt.Errorf("unknown query parameter %q", key)
+		}
+		if fd.IsMap() || fd.Message() != nil {
+			return fmt.Errorf("query parameter %q can't be set from the query", key)
+		}
+
+		if !fd.IsList() && len(values) > 1 {
+			return fmt.Errorf("query parameter %q is given more than once", key)
+		}
+
+		for _, value := range values {
+			parsed, err := parseQueryValue(fd, value)
+			if err != nil {
+				return fmt.Errorf("invalid value for query parameter %q: %w", key, err)
+			}
+
+			if fd.IsList() {
+				m.Mutable(fd).List().Append(parsed)
+			} else {
+				m.Set(fd, parsed)
+			}
+		}
 	}
 	return nil
 }
 
-func SendProtoResponse(w http.ResponseWriter, data proto.Message) {
-	response, _ := proto.Marshal(data)
+func parseQueryValue(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		return protoreflect.ValueOfBytes(decoded), err
+	case protoreflect.BoolKind:
+		parsed, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(parsed), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(parsed)), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(parsed)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(parsed), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(parsed)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(parsed), err
+	case protoreflect.FloatKind:
+		parsed, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(parsed)), err
+	case protoreflect.DoubleKind:
+		parsed, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(parsed), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
+
+// SendProtoResponse answers with protojson when the client prefers application/json, protobuf otherwise.
+func SendProtoResponse(w http.ResponseWriter, r *http.Request, data proto.Message) {
+	var response []byte
+	if WantsJSON(r) {
+		response, _ = protojson.Marshal(data)
+		w.Header().Set("Content-Type", ContentTypeJSON)
+	} else {
+		response, _ = proto.Marshal(data)
+		w.Header().Set("Content-Type", ContentTypeProto)
+	}
 
-	w.Header().Set("Content-Type", "application/x-protobuf")
 	if _, err := w.Write(response); err != nil {
-		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		SendError(w, codes.Internal, "Failed to write response")
 		return
 	}
 }
 
+// SendError writes a google.rpc.Status json body, the http status is the one GrpcCodeToHTTP maps the code to.
+func SendError(w http.ResponseWriter, code codes.Code, message string) {
+	SendErrorStatus(w, GrpcCodeToHTTP(code), code, message)
+}
+
+// SendErrorStatus is SendError with an http status that is more specific than the one of the code.
+func SendErrorStatus(w http.ResponseWriter, httpStatus int, code codes.Code, message string) {
+	body, _ := protojson.Marshal(status.New(code, message).Proto())
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(body)
+}
+
+// SendStatusError sends the code and message of a grpc status error, other errors are sent as Unknown.
+func SendStatusError(w http.ResponseWriter, err error) {
+	st, _ := status.FromError(err)
+	SendError(w, st.Code(), st.Message())
+}
+
+// WantsJSON compares the quality the Accept header gives json and protobuf,
+// without either of them the response follows the request Content-Type.
+func WantsJSON(r *http.Request) bool {
+	var jsonQ, protoQ float64
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accepted)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case ContentTypeJSON:
+			jsonQ = max(jsonQ, q)
+		case ContentTypeProto:
+			protoQ = max(protoQ, q)
+		}
+	}
+
+	if jsonQ == 0 && protoQ == 0 {
+		return isJSON(r.Header.Get("Content-Type"))
+	}
+	return jsonQ > protoQ
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentTypeJSON
+}
+
 func EnsureBase64Password(password string, method string) string {
 	// First check if it's already a valid base64 string
 	decodedBytes, err := base64.StdEncoding.DecodeString(password)
@@ -102,6 +261,21 @@ func GrpcCodeToHTTP(code codes.Code) int {
 	}
 }
 
+// HTTPToGrpcCode maps an HTTP status back to the gRPC code GrpcCodeToHTTP maps to it,
+// statuses shared by several codes give the first one and unknown statuses give Unknown.
+func HTTPToGrpcCode(httpStatus int) codes.Code {
+	if httpStatus >= 200 && httpStatus < 300 {
+		return codes.OK
+	}
+	for code := codes.Canceled; code <= codes.Unauthenticated; code++ {
+		// Unknown takes the default status, Internal is the code that owns it
+		if code != codes.Unknown && GrpcCodeToHTTP(code) == httpStatus {
+			return code
+		}
+	}
+	return codes.Unknown
+}
+
 // InterceptNotFound checks for errors ending with "not found."
 // and wraps them as gRPC NotFound.
 func InterceptNotFound(err error) error {
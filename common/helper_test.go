@@ -0,0 +1,149 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	tests := []struct {
+		contentType string
+		accept      string
+		json        bool
+	}{
+		{"", "", false},
+		{ContentTypeProto, "", false},
+		{ContentTypeJSON + "; charset=utf-8", "", true},
+		{ContentTypeProto, ContentTypeJSON, true},
+		{ContentTypeJSON, ContentTypeProto, false},
+		{"", ContentTypeProto + ";q=0.5, " + ContentTypeJSON, true},
+		{"", ContentTypeJSON + ";q=0.5, " + ContentTypeProto, false},
+	}
+
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Content-Type", tt.contentType)
+		request.Header.Set("Accept", tt.accept)
+
+		if got := WantsJSON(request); got != tt.json {
+			t.Errorf("Content-Type %q, Accept %q: WantsJSON = %v", tt.contentType, tt.accept, got)
+		}
+	}
+}
+
+func TestReadProtoBodyJSON(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"email": "user@example.com", "unknown": 1}`))
+	request.Header.Set("Content-Type", ContentTypeJSON)
+
+	var user UserRequest
+	if err := ReadProtoBody(request, &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.GetEmail() != "user@example.com" {
+		t.Errorf("email = %q", user.GetEmail())
+	}
+
+	empty := httptest.NewRequest(http.MethodGet, "/", nil)
+	empty.Header.Set("Content-Type", ContentTypeJSON)
+	if err := ReadProtoBody(empty, &user); err != nil {
+		t.Errorf("empty json body: %v", err)
+	}
+}
+
+func TestSendProtoResponse(t *testing.T) {
+	user := &UserRequest{Email: "user@example.com"}
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", ContentTypeJSON)
+	recorder := httptest.NewRecorder()
+	SendProtoResponse(recorder, request, user)
+
+	decoded := &UserRequest{}
+	if recorder.Header().Get("Content-Type") != ContentTypeJSON {
+		t.Fatalf("Content-Type = %q", recorder.Header().Get("Content-Type"))
+	}
+	if err := protojson.Unmarshal(recorder.Body.Bytes(), decoded); err != nil || !proto.Equal(decoded, user) {
+		t.Errorf("json response %q decoded to %v: %v", recorder.Body.String(), decoded, err)
+	}
+
+	recorder = httptest.NewRecorder()
+	SendProtoResponse(recorder, httptest.NewRequest(http.MethodGet, "/", nil), user)
+
+	decoded = &UserRequest{}
+	if recorder.Header().Get("Content-Type") != ContentTypeProto {
+		t.Fatalf("Content-Type = %q", recorder.Header().Get("Content-Type"))
+	}
+	if err := proto.Unmarshal(recorder.Body.Bytes(), decoded); err != nil || !proto.Equal(decoded, user) {
+		t.Errorf("protobuf response decoded to %v: %v", decoded, err)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	SendError(recorder, codes.NotFound, "user not found")
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("status = %d", recorder.Code)
+	}
+	if recorder.Header().Get("Content-Type") != ContentTypeJSON {
+		t.Errorf("Content-Type = %q", recorder.Header().Get("Content-Type"))
+	}
+
+	var body struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Code != codes.NotFound || body.Message != "user not found" {
+		t.Errorf("body %q decoded to %+v: %v", recorder.Body.String(), body, err)
+	}
+}
+
+func TestHTTPToGrpcCode(t *testing.T) {
+	for _, code := range []codes.Code{codes.OK, codes.InvalidArgument, codes.PermissionDenied, codes.NotFound, codes.FailedPrecondition, codes.Internal, codes.Unavailable} {
+		if got := HTTPToGrpcCode(GrpcCodeToHTTP(code)); got != code {
+			t.Errorf("%v mapped back to %v", code, got)
+		}
+	}
+	if got := HTTPToGrpcCode(http.StatusNoContent); got != codes.OK {
+		t.Errorf("204 mapped to %v", got)
+	}
+	if got := HTTPToGrpcCode(http.StatusTeapot); got != codes.Unknown {
+		t.Errorf("418 mapped to %v", got)
+	}
+}
+
+func TestReadProtoQuery(t *testing.T) {
+	query := url.Values{"reset": {"true"}, "type": {"UserStat"}, "name": {"user@example.com"}}
+
+	var request StatRequest
+	if err := ReadProtoQuery(query, &request); err != nil {
+		t.Fatal(err)
+	}
+	if !request.GetReset_() || request.GetType() != StatType_UserStat || request.GetName() != "user@example.com" {
+		t.Errorf("decoded %v", &request)
+	}
+
+	var remove RemoveUsersRequest
+	if err := ReadProtoQuery(url.Values{"emails": {"a", "b"}}, &remove); err != nil || len(remove.GetEmails()) != 2 {
+		t.Errorf("repeated parameter decoded to %v: %v", remove.GetEmails(), err)
+	}
+
+	for _, invalid := range []url.Values{
+		{"unknown": {"1"}},
+		{"reset": {"yes please"}},
+		{"name": {"a", "b"}},
+		{"type": {"NoSuchType"}},
+	} {
+		if err := ReadProtoQuery(invalid, &StatRequest{}); err == nil {
+			t.Errorf("%v should be rejected", invalid)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.10
 // 	protoc        v6.31.1
 // source: common/service.proto
 
@@ -122,6 +122,58 @@ func (StatType) EnumDescriptor() ([]byte, []int) {
 	return file_common_service_proto_rawDescGZIP(), []int{1}
 }
 
+type RestrictionReason int32
+
+const (
+	RestrictionReason_ReasonUnspecified RestrictionReason = 0
+	RestrictionReason_DataLimitReached  RestrictionReason = 1
+	RestrictionReason_Expired           RestrictionReason = 2
+	RestrictionReason_IpLimitExceeded   RestrictionReason = 3
+)
+
+// Enum value maps for RestrictionReason.
+var (
+	RestrictionReason_name = map[int32]string{
+		0: "ReasonUnspecified",
+		1: "DataLimitReached",
+		2: "Expired",
+		3: "IpLimitExceeded",
+	}
+	RestrictionReason_value = map[string]int32{
+		"ReasonUnspecified": 0,
+		"DataLimitReached":  1,
+		"Expired":           2,
+		"IpLimitExceeded":   3,
+	}
+)
+
+func (x RestrictionReason) Enum() *RestrictionReason {
+	p := new(RestrictionReason)
+	*p = x
+	return p
+}
+
+func (x RestrictionReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RestrictionReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_service_proto_enumTypes[2].Descriptor()
+}
+
+func (RestrictionReason) Type() protoreflect.EnumType {
+	return &file_common_service_proto_enumTypes[2]
+}
+
+func (x RestrictionReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RestrictionReason.Descriptor instead.
+func (RestrictionReason) EnumDescriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{2}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -164,6 +216,7 @@ type BaseInfoResponse struct {
 	Started       bool                   `protobuf:"varint,1,opt,name=started,proto3" json:"started,omitempty"`
 	CoreVersion   string                 `protobuf:"bytes,2,opt,name=core_version,json=coreVersion,proto3" json:"core_version,omitempty"`
 	GateVersion   string                 `protobuf:"bytes,3,opt,name=Gate_version,json=GateVersion,proto3" json:"Gate_version,omitempty"`
+	SessionId     string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -219,6 +272,13 @@ func (x *BaseInfoResponse) GetGateVersion() string {
 	return ""
 }
 
+func (x *BaseInfoResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
 type Backend struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Type            BackendType            `protobuf:"varint,1,opt,name=type,proto3,enum=service.BackendType" json:"type,omitempty"`
@@ -226,8 +286,11 @@ type Backend struct {
 	Users           []*User                `protobuf:"bytes,3,rep,name=users,proto3" json:"users,omitempty"`
 	KeepAlive       uint64                 `protobuf:"varint,4,opt,name=keep_alive,json=keepAlive,proto3" json:"keep_alive,omitempty"`
 	ExcludeInbounds []string               `protobuf:"bytes,5,rep,name=exclude_inbounds,json=excludeInbounds,proto3" json:"exclude_inbounds,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	Detached        bool                   `protobuf:"varint,6,opt,name=detached,proto3" json:"detached,omitempty"`
+	// secondary sessions can read stats and logs without taking control of the core
+	Secondary     bool `protobuf:"varint,7,opt,name=secondary,proto3" json:"secondary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Backend) Reset() {
@@ -295,6 +358,20 @@ func (x *Backend) GetExcludeInbounds() []string {
 	return nil
 }
 
+func (x *Backend) GetDetached() bool {
+	if x != nil {
+		return x.Detached
+	}
+	return false
+}
+
+func (x *Backend) GetSecondary() bool {
+	if x != nil {
+		return x.Secondary
+	}
+	return false
+}
+
 // log
 type Log struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1010,19 +1087,171 @@ func (x *Shadowsocks) GetMethod() string {
 	return ""
 }
 
+// socks and http accounts use the user email as username
+type Socks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Password      string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Socks) Reset() {
+	*x = Socks{}
+	mi := &file_common_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Socks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Socks) ProtoMessage() {}
+
+func (x *Socks) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Socks.ProtoReflect.Descriptor instead.
+func (*Socks) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Socks) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type Http struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Password      string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Http) Reset() {
+	*x = Http{}
+	mi := &file_common_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Http) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Http) ProtoMessage() {}
+
+func (x *Http) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Http.ProtoReflect.Descriptor instead.
+func (*Http) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Http) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type Wireguard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PublicKey     string                 `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	PresharedKey  string                 `protobuf:"bytes,2,opt,name=preshared_key,json=presharedKey,proto3" json:"preshared_key,omitempty"`
+	AllowedIps    []string               `protobuf:"bytes,3,rep,name=allowed_ips,json=allowedIps,proto3" json:"allowed_ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Wireguard) Reset() {
+	*x = Wireguard{}
+	mi := &file_common_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wireguard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wireguard) ProtoMessage() {}
+
+func (x *Wireguard) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wireguard.ProtoReflect.Descriptor instead.
+func (*Wireguard) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Wireguard) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *Wireguard) GetPresharedKey() string {
+	if x != nil {
+		return x.PresharedKey
+	}
+	return ""
+}
+
+func (x *Wireguard) GetAllowedIps() []string {
+	if x != nil {
+		return x.AllowedIps
+	}
+	return nil
+}
+
 type Proxy struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Vmess         *Vmess                 `protobuf:"bytes,1,opt,name=vmess,proto3" json:"vmess,omitempty"`
 	Vless         *Vless                 `protobuf:"bytes,2,opt,name=vless,proto3" json:"vless,omitempty"`
 	Trojan        *Trojan                `protobuf:"bytes,3,opt,name=trojan,proto3" json:"trojan,omitempty"`
 	Shadowsocks   *Shadowsocks           `protobuf:"bytes,4,opt,name=shadowsocks,proto3" json:"shadowsocks,omitempty"`
+	Socks         *Socks                 `protobuf:"bytes,5,opt,name=socks,proto3" json:"socks,omitempty"`
+	Http          *Http                  `protobuf:"bytes,6,opt,name=http,proto3" json:"http,omitempty"`
+	Wireguard     *Wireguard             `protobuf:"bytes,7,opt,name=wireguard,proto3" json:"wireguard,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Proxy) Reset() {
 	*x = Proxy{}
-	mi := &file_common_service_proto_msgTypes[15]
+	mi := &file_common_service_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1034,7 +1263,7 @@ func (x *Proxy) String() string {
 func (*Proxy) ProtoMessage() {}
 
 func (x *Proxy) ProtoReflect() protoreflect.Message {
-	mi := &file_common_service_proto_msgTypes[15]
+	mi := &file_common_service_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1047,7 +1276,7 @@ func (x *Proxy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proxy.ProtoReflect.Descriptor instead.
 func (*Proxy) Descriptor() ([]byte, []int) {
-	return file_common_service_proto_rawDescGZIP(), []int{15}
+	return file_common_service_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *Proxy) GetVmess() *Vmess {
@@ -1078,18 +1307,44 @@ func (x *Proxy) GetShadowsocks() *Shadowsocks {
 	return nil
 }
 
+func (x *Proxy) GetSocks() *Socks {
+	if x != nil {
+		return x.Socks
+	}
+	return nil
+}
+
+func (x *Proxy) GetHttp() *Http {
+	if x != nil {
+		return x.Http
+	}
+	return nil
+}
+
+func (x *Proxy) GetWireguard() *Wireguard {
+	if x != nil {
+		return x.Wireguard
+	}
+	return nil
+}
+
 type User struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	Proxies       *Proxy                 `protobuf:"bytes,2,opt,name=proxies,proto3" json:"proxies,omitempty"`
 	Inbounds      []string               `protobuf:"bytes,3,rep,name=inbounds,proto3" json:"inbounds,omitempty"`
+	DataLimit     uint64                 `protobuf:"varint,4,opt,name=data_limit,json=dataLimit,proto3" json:"data_limit,omitempty"`
+	UsedTraffic   uint64                 `protobuf:"varint,5,opt,name=used_traffic,json=usedTraffic,proto3" json:"used_traffic,omitempty"`
+	ExpireAt      int64                  `protobuf:"varint,6,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	MaxIps        uint32                 `protobuf:"varint,7,opt,name=max_ips,json=maxIps,proto3" json:"max_ips,omitempty"`
+	Level         uint32                 `protobuf:"varint,8,opt,name=level,proto3" json:"level,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_common_service_proto_msgTypes[16]
+	mi := &file_common_service_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1101,7 +1356,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_common_service_proto_msgTypes[16]
+	mi := &file_common_service_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1114,7 +1369,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_common_service_proto_rawDescGZIP(), []int{16}
+	return file_common_service_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *User) GetEmail() string {
@@ -1138,6 +1393,41 @@ func (x *User) GetInbounds() []string {
 	return nil
 }
 
+func (x *User) GetDataLimit() uint64 {
+	if x != nil {
+		return x.DataLimit
+	}
+	return 0
+}
+
+func (x *User) GetUsedTraffic() uint64 {
+	if x != nil {
+		return x.UsedTraffic
+	}
+	return 0
+}
+
+func (x *User) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+func (x *User) GetMaxIps() uint32 {
+	if x != nil {
+		return x.MaxIps
+	}
+	return 0
+}
+
+func (x *User) GetLevel() uint32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
 type Users struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
@@ -1147,7 +1437,7 @@ type Users struct {
 
 func (x *Users) Reset() {
 	*x = Users{}
-	mi := &file_common_service_proto_msgTypes[17]
+	mi := &file_common_service_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1159,7 +1449,7 @@ func (x *Users) String() string {
 func (*Users) ProtoMessage() {}
 
 func (x *Users) ProtoReflect() protoreflect.Message {
-	mi := &file_common_service_proto_msgTypes[17]
+	mi := &file_common_service_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1172,7 +1462,7 @@ func (x *Users) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Users.ProtoReflect.Descriptor instead.
 func (*Users) Descriptor() ([]byte, []int) {
-	return file_common_service_proto_rawDescGZIP(), []int{17}
+	return file_common_service_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Users) GetUsers() []*User {
@@ -1182,56 +1472,1527 @@ func (x *Users) GetUsers() []*User {
 	return nil
 }
 
-var File_common_service_proto protoreflect.FileDescriptor
+type RemoveUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emails        []string               `protobuf:"bytes,1,rep,name=emails,proto3" json:"emails,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_common_service_proto_rawDesc = "" +
-	"\n" +
-	"\x14common/service.proto\x12\aservice\"\a\n" +
-	"\x05Empty\"r\n" +
-	"\x10BaseInfoResponse\x12\x18\n" +
-	"\astarted\x18\x01 \x01(\bR\astarted\x12!\n" +
-	"\fcore_version\x18\x02 \x01(\tR\vcoreVersion\x12!\n" +
-	"\fGate_version\x18\x03 \x01(\tR\vGateVersion\"\xba\x01\n" +
-	"\aBackend\x12(\n" +
-	"\x04type\x18\x01 \x01(\x0e2\x14.service.BackendTypeR\x04type\x12\x16\n" +
-	"\x06config\x18\x02 \x01(\tR\x06config\x12#\n" +
-	"\x05users\x18\x03 \x03(\v2\r.service.UserR\x05users\x12\x1d\n" +
-	"\n" +
-	"keep_alive\x18\x04 \x01(\x04R\tkeepAlive\x12)\n" +
-	"\x10exclude_inbounds\x18\x05 \x03(\tR\x0fexcludeInbounds\"\x1d\n" +
-	"\x03Log\x12\x16\n" +
-	"\x06detail\x18\x01 \x01(\tR\x06detail\"X\n" +
-	"\x04Stat\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
-	"\x04link\x18\x03 \x01(\tR\x04link\x12\x14\n" +
-	"\x05value\x18\x04 \x01(\x03R\x05value\"3\n" +
-	"\fStatResponse\x12#\n" +
-	"\x05stats\x18\x01 \x03(\v2\r.service.StatR\x05stats\"^\n" +
-	"\vStatRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
-	"\x05reset\x18\x02 \x01(\bR\x05reset\x12%\n" +
-	"\x04type\x18\x03 \x01(\x0e2\x11.service.StatTypeR\x04type\">\n" +
-	"\x12OnlineStatResponse\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value\"\xa6\x01\n" +
-	"\x19StatsOnlineIpListResponse\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12=\n" +
-	"\x03ips\x18\x02 \x03(\v2+.service.StatsOnlineIpListResponse.IpsEntryR\x03ips\x1a6\n" +
-	"\bIpsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xac\x02\n" +
-	"\x14BackendStatsResponse\x12#\n" +
-	"\rnum_goroutine\x18\x01 \x01(\rR\fnumGoroutine\x12\x15\n" +
-	"\x06num_gc\x18\x02 \x01(\rR\x05numGc\x12\x14\n" +
-	"\x05alloc\x18\x03 \x01(\x04R\x05alloc\x12\x1f\n" +
-	"\vtotal_alloc\x18\x04 \x01(\x04R\n" +
-	"totalAlloc\x12\x10\n" +
-	"\x03sys\x18\x05 \x01(\x04R\x03sys\x12\x18\n" +
-	"\amallocs\x18\x06 \x01(\x04R\amallocs\x12\x14\n" +
-	"\x05frees\x18\a \x01(\x04R\x05frees\x12!\n" +
-	"\flive_objects\x18\b \x01(\x04R\vliveObjects\x12$\n" +
-	"\x0epause_total_ns\x18\t \x01(\x04R\fpauseTotalNs\x12\x16\n" +
+func (x *RemoveUsersRequest) Reset() {
+	*x = RemoveUsersRequest{}
+	mi := &file_common_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUsersRequest) ProtoMessage() {}
+
+func (x *RemoveUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUsersRequest.ProtoReflect.Descriptor instead.
+func (*RemoveUsersRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RemoveUsersRequest) GetEmails() []string {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
+}
+
+type RemoveUsersResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Removed []string               `protobuf:"bytes,1,rep,name=removed,proto3" json:"removed,omitempty"`
+	Unknown []string               `protobuf:"bytes,2,rep,name=unknown,proto3" json:"unknown,omitempty"`
+	// restarted is set when the core was restarted to apply the change.
+	Restarted     bool `protobuf:"varint,3,opt,name=restarted,proto3" json:"restarted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUsersResponse) Reset() {
+	*x = RemoveUsersResponse{}
+	mi := &file_common_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUsersResponse) ProtoMessage() {}
+
+func (x *RemoveUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUsersResponse.ProtoReflect.Descriptor instead.
+func (*RemoveUsersResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RemoveUsersResponse) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *RemoveUsersResponse) GetUnknown() []string {
+	if x != nil {
+		return x.Unknown
+	}
+	return nil
+}
+
+func (x *RemoveUsersResponse) GetRestarted() bool {
+	if x != nil {
+		return x.Restarted
+	}
+	return false
+}
+
+type UserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRequest) Reset() {
+	*x = UserRequest{}
+	mi := &file_common_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRequest) ProtoMessage() {}
+
+func (x *UserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRequest.ProtoReflect.Descriptor instead.
+func (*UserRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type UserInbound struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Tag            string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Protocol       string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Normalizations []string               `protobuf:"bytes,3,rep,name=normalizations,proto3" json:"normalizations,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UserInbound) Reset() {
+	*x = UserInbound{}
+	mi := &file_common_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserInbound) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserInbound) ProtoMessage() {}
+
+func (x *UserInbound) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserInbound.ProtoReflect.Descriptor instead.
+func (*UserInbound) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UserInbound) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *UserInbound) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *UserInbound) GetNormalizations() []string {
+	if x != nil {
+		return x.Normalizations
+	}
+	return nil
+}
+
+type LoadedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Level         uint32                 `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Inbounds      []*UserInbound         `protobuf:"bytes,3,rep,name=inbounds,proto3" json:"inbounds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadedUser) Reset() {
+	*x = LoadedUser{}
+	mi := &file_common_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadedUser) ProtoMessage() {}
+
+func (x *LoadedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadedUser.ProtoReflect.Descriptor instead.
+func (*LoadedUser) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *LoadedUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LoadedUser) GetLevel() uint32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *LoadedUser) GetInbounds() []*UserInbound {
+	if x != nil {
+		return x.Inbounds
+	}
+	return nil
+}
+
+type LoadedUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*LoadedUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadedUsersResponse) Reset() {
+	*x = LoadedUsersResponse{}
+	mi := &file_common_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadedUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadedUsersResponse) ProtoMessage() {}
+
+func (x *LoadedUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadedUsersResponse.ProtoReflect.Descriptor instead.
+func (*LoadedUsersResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *LoadedUsersResponse) GetUsers() []*LoadedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type RestrictedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Reason        RestrictionReason      `protobuf:"varint,2,opt,name=reason,proto3,enum=service.RestrictionReason" json:"reason,omitempty"`
+	RestrictedAt  int64                  `protobuf:"varint,3,opt,name=restricted_at,json=restrictedAt,proto3" json:"restricted_at,omitempty"`
+	UsedTraffic   uint64                 `protobuf:"varint,4,opt,name=used_traffic,json=usedTraffic,proto3" json:"used_traffic,omitempty"`
+	DataLimit     uint64                 `protobuf:"varint,5,opt,name=data_limit,json=dataLimit,proto3" json:"data_limit,omitempty"`
+	ExpireAt      int64                  `protobuf:"varint,6,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	Ips           []string               `protobuf:"bytes,7,rep,name=ips,proto3" json:"ips,omitempty"`
+	ReleaseAt     int64                  `protobuf:"varint,8,opt,name=release_at,json=releaseAt,proto3" json:"release_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestrictedUser) Reset() {
+	*x = RestrictedUser{}
+	mi := &file_common_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestrictedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestrictedUser) ProtoMessage() {}
+
+func (x *RestrictedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestrictedUser.ProtoReflect.Descriptor instead.
+func (*RestrictedUser) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RestrictedUser) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *RestrictedUser) GetReason() RestrictionReason {
+	if x != nil {
+		return x.Reason
+	}
+	return RestrictionReason_ReasonUnspecified
+}
+
+func (x *RestrictedUser) GetRestrictedAt() int64 {
+	if x != nil {
+		return x.RestrictedAt
+	}
+	return 0
+}
+
+func (x *RestrictedUser) GetUsedTraffic() uint64 {
+	if x != nil {
+		return x.UsedTraffic
+	}
+	return 0
+}
+
+func (x *RestrictedUser) GetDataLimit() uint64 {
+	if x != nil {
+		return x.DataLimit
+	}
+	return 0
+}
+
+func (x *RestrictedUser) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+func (x *RestrictedUser) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+func (x *RestrictedUser) GetReleaseAt() int64 {
+	if x != nil {
+		return x.ReleaseAt
+	}
+	return 0
+}
+
+type RestrictedUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*RestrictedUser      `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestrictedUsersResponse) Reset() {
+	*x = RestrictedUsersResponse{}
+	mi := &file_common_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestrictedUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestrictedUsersResponse) ProtoMessage() {}
+
+func (x *RestrictedUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestrictedUsersResponse.ProtoReflect.Descriptor instead.
+func (*RestrictedUsersResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestrictedUsersResponse) GetUsers() []*RestrictedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type EnforcementEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Reason        RestrictionReason      `protobuf:"varint,2,opt,name=reason,proto3,enum=service.RestrictionReason" json:"reason,omitempty"`
+	Released      bool                   `protobuf:"varint,3,opt,name=released,proto3" json:"released,omitempty"`
+	Time          int64                  `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	Ips           []string               `protobuf:"bytes,5,rep,name=ips,proto3" json:"ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnforcementEvent) Reset() {
+	*x = EnforcementEvent{}
+	mi := &file_common_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnforcementEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnforcementEvent) ProtoMessage() {}
+
+func (x *EnforcementEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnforcementEvent.ProtoReflect.Descriptor instead.
+func (*EnforcementEvent) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *EnforcementEvent) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *EnforcementEvent) GetReason() RestrictionReason {
+	if x != nil {
+		return x.Reason
+	}
+	return RestrictionReason_ReasonUnspecified
+}
+
+func (x *EnforcementEvent) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+func (x *EnforcementEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *EnforcementEvent) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+type EnforcementEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         int64                  `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnforcementEventsRequest) Reset() {
+	*x = EnforcementEventsRequest{}
+	mi := &file_common_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnforcementEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnforcementEventsRequest) ProtoMessage() {}
+
+func (x *EnforcementEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnforcementEventsRequest.ProtoReflect.Descriptor instead.
+func (*EnforcementEventsRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *EnforcementEventsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type EnforcementEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*EnforcementEvent    `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnforcementEventsResponse) Reset() {
+	*x = EnforcementEventsResponse{}
+	mi := &file_common_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnforcementEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnforcementEventsResponse) ProtoMessage() {}
+
+func (x *EnforcementEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnforcementEventsResponse.ProtoReflect.Descriptor instead.
+func (*EnforcementEventsResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *EnforcementEventsResponse) GetEvents() []*EnforcementEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ExpirationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Within        int64                  `protobuf:"varint,1,opt,name=within,proto3" json:"within,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpirationsRequest) Reset() {
+	*x = ExpirationsRequest{}
+	mi := &file_common_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpirationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpirationsRequest) ProtoMessage() {}
+
+func (x *ExpirationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpirationsRequest.ProtoReflect.Descriptor instead.
+func (*ExpirationsRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ExpirationsRequest) GetWithin() int64 {
+	if x != nil {
+		return x.Within
+	}
+	return 0
+}
+
+type UserExpiration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	ExpireAt      int64                  `protobuf:"varint,2,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserExpiration) Reset() {
+	*x = UserExpiration{}
+	mi := &file_common_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserExpiration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserExpiration) ProtoMessage() {}
+
+func (x *UserExpiration) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserExpiration.ProtoReflect.Descriptor instead.
+func (*UserExpiration) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UserExpiration) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UserExpiration) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+type ExpirationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*UserExpiration      `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpirationsResponse) Reset() {
+	*x = ExpirationsResponse{}
+	mi := &file_common_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpirationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpirationsResponse) ProtoMessage() {}
+
+func (x *ExpirationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpirationsResponse.ProtoReflect.Descriptor instead.
+func (*ExpirationsResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ExpirationsResponse) GetUsers() []*UserExpiration {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+// Policy
+type Policy struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Level           uint32                 `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`
+	Handshake       *uint32                `protobuf:"varint,2,opt,name=handshake,proto3,oneof" json:"handshake,omitempty"`
+	ConnIdle        *uint32                `protobuf:"varint,3,opt,name=conn_idle,json=connIdle,proto3,oneof" json:"conn_idle,omitempty"`
+	UplinkOnly      *uint32                `protobuf:"varint,4,opt,name=uplink_only,json=uplinkOnly,proto3,oneof" json:"uplink_only,omitempty"`
+	DownlinkOnly    *uint32                `protobuf:"varint,5,opt,name=downlink_only,json=downlinkOnly,proto3,oneof" json:"downlink_only,omitempty"`
+	BufferSize      *int32                 `protobuf:"varint,6,opt,name=buffer_size,json=bufferSize,proto3,oneof" json:"buffer_size,omitempty"`
+	StatsUserOnline bool                   `protobuf:"varint,7,opt,name=stats_user_online,json=statsUserOnline,proto3" json:"stats_user_online,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Policy) Reset() {
+	*x = Policy{}
+	mi := &file_common_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policy) ProtoMessage() {}
+
+func (x *Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
+func (*Policy) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *Policy) GetLevel() uint32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *Policy) GetHandshake() uint32 {
+	if x != nil && x.Handshake != nil {
+		return *x.Handshake
+	}
+	return 0
+}
+
+func (x *Policy) GetConnIdle() uint32 {
+	if x != nil && x.ConnIdle != nil {
+		return *x.ConnIdle
+	}
+	return 0
+}
+
+func (x *Policy) GetUplinkOnly() uint32 {
+	if x != nil && x.UplinkOnly != nil {
+		return *x.UplinkOnly
+	}
+	return 0
+}
+
+func (x *Policy) GetDownlinkOnly() uint32 {
+	if x != nil && x.DownlinkOnly != nil {
+		return *x.DownlinkOnly
+	}
+	return 0
+}
+
+func (x *Policy) GetBufferSize() int32 {
+	if x != nil && x.BufferSize != nil {
+		return *x.BufferSize
+	}
+	return 0
+}
+
+func (x *Policy) GetStatsUserOnline() bool {
+	if x != nil {
+		return x.StatsUserOnline
+	}
+	return false
+}
+
+type Policies struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Levels        []*Policy              `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Policies) Reset() {
+	*x = Policies{}
+	mi := &file_common_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Policies) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policies) ProtoMessage() {}
+
+func (x *Policies) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policies.ProtoReflect.Descriptor instead.
+func (*Policies) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *Policies) GetLevels() []*Policy {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+type SyncUsersResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Added     uint32                 `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Removed   uint32                 `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	Updated   uint32                 `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Unchanged uint32                 `protobuf:"varint,4,opt,name=unchanged,proto3" json:"unchanged,omitempty"`
+	// restarted is set when the core was restarted to apply the change.
+	Restarted     bool `protobuf:"varint,5,opt,name=restarted,proto3" json:"restarted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncUsersResponse) Reset() {
+	*x = SyncUsersResponse{}
+	mi := &file_common_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncUsersResponse) ProtoMessage() {}
+
+func (x *SyncUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncUsersResponse.ProtoReflect.Descriptor instead.
+func (*SyncUsersResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SyncUsersResponse) GetAdded() uint32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *SyncUsersResponse) GetRemoved() uint32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *SyncUsersResponse) GetUpdated() uint32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *SyncUsersResponse) GetUnchanged() uint32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+func (x *SyncUsersResponse) GetRestarted() bool {
+	if x != nil {
+		return x.Restarted
+	}
+	return false
+}
+
+// Ban is a source ip that failed authentication, it is locked out until banned_until when that is set.
+type Ban struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Failures      uint32                 `protobuf:"varint,2,opt,name=failures,proto3" json:"failures,omitempty"`
+	BannedUntil   int64                  `protobuf:"varint,3,opt,name=banned_until,json=bannedUntil,proto3" json:"banned_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ban) Reset() {
+	*x = Ban{}
+	mi := &file_common_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ban) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ban) ProtoMessage() {}
+
+func (x *Ban) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ban.ProtoReflect.Descriptor instead.
+func (*Ban) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *Ban) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Ban) GetFailures() uint32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *Ban) GetBannedUntil() int64 {
+	if x != nil {
+		return x.BannedUntil
+	}
+	return 0
+}
+
+type BansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bans          []*Ban                 `protobuf:"bytes,1,rep,name=bans,proto3" json:"bans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BansResponse) Reset() {
+	*x = BansResponse{}
+	mi := &file_common_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BansResponse) ProtoMessage() {}
+
+func (x *BansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BansResponse.ProtoReflect.Descriptor instead.
+func (*BansResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *BansResponse) GetBans() []*Ban {
+	if x != nil {
+		return x.Bans
+	}
+	return nil
+}
+
+// ClearBansRequest clears the given ips, or every ban when none are given.
+type ClearBansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ips           []string               `protobuf:"bytes,1,rep,name=ips,proto3" json:"ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearBansRequest) Reset() {
+	*x = ClearBansRequest{}
+	mi := &file_common_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearBansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearBansRequest) ProtoMessage() {}
+
+func (x *ClearBansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearBansRequest.ProtoReflect.Descriptor instead.
+func (*ClearBansRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ClearBansRequest) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+type ClearBansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cleared       uint32                 `protobuf:"varint,1,opt,name=cleared,proto3" json:"cleared,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearBansResponse) Reset() {
+	*x = ClearBansResponse{}
+	mi := &file_common_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearBansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearBansResponse) ProtoMessage() {}
+
+func (x *ClearBansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearBansResponse.ProtoReflect.Descriptor instead.
+func (*ClearBansResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ClearBansResponse) GetCleared() uint32 {
+	if x != nil {
+		return x.Cleared
+	}
+	return 0
+}
+
+// AuditChanges summarizes what a control-plane operation changed.
+type AuditChanges struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []string               `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	UsersAdded    uint32                 `protobuf:"varint,2,opt,name=users_added,json=usersAdded,proto3" json:"users_added,omitempty"`
+	UsersRemoved  uint32                 `protobuf:"varint,3,opt,name=users_removed,json=usersRemoved,proto3" json:"users_removed,omitempty"`
+	UsersUpdated  uint32                 `protobuf:"varint,4,opt,name=users_updated,json=usersUpdated,proto3" json:"users_updated,omitempty"`
+	ConfigHash    string                 `protobuf:"bytes,5,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	CoreRestarted bool                   `protobuf:"varint,6,opt,name=core_restarted,json=coreRestarted,proto3" json:"core_restarted,omitempty"`
+	CoreStopped   bool                   `protobuf:"varint,7,opt,name=core_stopped,json=coreStopped,proto3" json:"core_stopped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditChanges) Reset() {
+	*x = AuditChanges{}
+	mi := &file_common_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditChanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditChanges) ProtoMessage() {}
+
+func (x *AuditChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditChanges.ProtoReflect.Descriptor instead.
+func (*AuditChanges) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AuditChanges) GetUsers() []string {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *AuditChanges) GetUsersAdded() uint32 {
+	if x != nil {
+		return x.UsersAdded
+	}
+	return 0
+}
+
+func (x *AuditChanges) GetUsersRemoved() uint32 {
+	if x != nil {
+		return x.UsersRemoved
+	}
+	return 0
+}
+
+func (x *AuditChanges) GetUsersUpdated() uint32 {
+	if x != nil {
+		return x.UsersUpdated
+	}
+	return 0
+}
+
+func (x *AuditChanges) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *AuditChanges) GetCoreRestarted() bool {
+	if x != nil {
+		return x.CoreRestarted
+	}
+	return false
+}
+
+func (x *AuditChanges) GetCoreStopped() bool {
+	if x != nil {
+		return x.CoreStopped
+	}
+	return false
+}
+
+type AuditEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	SessionId     string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Method        string                 `protobuf:"bytes,5,opt,name=method,proto3" json:"method,omitempty"`
+	Code          string                 `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`
+	Changes       *AuditChanges          `protobuf:"bytes,7,opt,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_common_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AuditEntry) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetChanges() *AuditChanges {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// AuditLogRequest filters the audit log, zero values match everything.
+type AuditLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         int64                  `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	Until         int64                  `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Limit         uint32                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditLogRequest) Reset() {
+	*x = AuditLogRequest{}
+	mi := &file_common_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLogRequest) ProtoMessage() {}
+
+func (x *AuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLogRequest.ProtoReflect.Descriptor instead.
+func (*AuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *AuditLogRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *AuditLogRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *AuditLogRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditLogRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditLogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*AuditEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditLogResponse) Reset() {
+	*x = AuditLogResponse{}
+	mi := &file_common_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLogResponse) ProtoMessage() {}
+
+func (x *AuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_common_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLogResponse.ProtoReflect.Descriptor instead.
+func (*AuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_common_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AuditLogResponse) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+var File_common_service_proto protoreflect.FileDescriptor
+
+const file_common_service_proto_rawDesc = "" +
+	"\n" +
+	"\x14common/service.proto\x12\aservice\"\a\n" +
+	"\x05Empty\"\x91\x01\n" +
+	"\x10BaseInfoResponse\x12\x18\n" +
+	"\astarted\x18\x01 \x01(\bR\astarted\x12!\n" +
+	"\fcore_version\x18\x02 \x01(\tR\vcoreVersion\x12!\n" +
+	"\fGate_version\x18\x03 \x01(\tR\vGateVersion\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\"\xf4\x01\n" +
+	"\aBackend\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.service.BackendTypeR\x04type\x12\x16\n" +
+	"\x06config\x18\x02 \x01(\tR\x06config\x12#\n" +
+	"\x05users\x18\x03 \x03(\v2\r.service.UserR\x05users\x12\x1d\n" +
+	"\n" +
+	"keep_alive\x18\x04 \x01(\x04R\tkeepAlive\x12)\n" +
+	"\x10exclude_inbounds\x18\x05 \x03(\tR\x0fexcludeInbounds\x12\x1a\n" +
+	"\bdetached\x18\x06 \x01(\bR\bdetached\x12\x1c\n" +
+	"\tsecondary\x18\a \x01(\bR\tsecondary\"\x1d\n" +
+	"\x03Log\x12\x16\n" +
+	"\x06detail\x18\x01 \x01(\tR\x06detail\"X\n" +
+	"\x04Stat\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04link\x18\x03 \x01(\tR\x04link\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x03R\x05value\"3\n" +
+	"\fStatResponse\x12#\n" +
+	"\x05stats\x18\x01 \x03(\v2\r.service.StatR\x05stats\"^\n" +
+	"\vStatRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05reset\x18\x02 \x01(\bR\x05reset\x12%\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x11.service.StatTypeR\x04type\">\n" +
+	"\x12OnlineStatResponse\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\"\xa6\x01\n" +
+	"\x19StatsOnlineIpListResponse\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12=\n" +
+	"\x03ips\x18\x02 \x03(\v2+.service.StatsOnlineIpListResponse.IpsEntryR\x03ips\x1a6\n" +
+	"\bIpsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xac\x02\n" +
+	"\x14BackendStatsResponse\x12#\n" +
+	"\rnum_goroutine\x18\x01 \x01(\rR\fnumGoroutine\x12\x15\n" +
+	"\x06num_gc\x18\x02 \x01(\rR\x05numGc\x12\x14\n" +
+	"\x05alloc\x18\x03 \x01(\x04R\x05alloc\x12\x1f\n" +
+	"\vtotal_alloc\x18\x04 \x01(\x04R\n" +
+	"totalAlloc\x12\x10\n" +
+	"\x03sys\x18\x05 \x01(\x04R\x03sys\x12\x18\n" +
+	"\amallocs\x18\x06 \x01(\x04R\amallocs\x12\x14\n" +
+	"\x05frees\x18\a \x01(\x04R\x05frees\x12!\n" +
+	"\flive_objects\x18\b \x01(\x04R\vliveObjects\x12$\n" +
+	"\x0epause_total_ns\x18\t \x01(\x04R\fpauseTotalNs\x12\x16\n" +
 	"\x06uptime\x18\n" +
 	" \x01(\rR\x06uptime\"\xfb\x01\n" +
 	"\x13SystemStatsResponse\x12\x1b\n" +
@@ -1250,18 +3011,148 @@ const file_common_service_proto_rawDesc = "" +
 	"\bpassword\x18\x01 \x01(\tR\bpassword\"A\n" +
 	"\vShadowsocks\x12\x1a\n" +
 	"\bpassword\x18\x01 \x01(\tR\bpassword\x12\x16\n" +
-	"\x06method\x18\x02 \x01(\tR\x06method\"\xb4\x01\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\"#\n" +
+	"\x05Socks\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"\"\n" +
+	"\x04Http\x12\x1a\n" +
+	"\bpassword\x18\x01 \x01(\tR\bpassword\"p\n" +
+	"\tWireguard\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x01 \x01(\tR\tpublicKey\x12#\n" +
+	"\rpreshared_key\x18\x02 \x01(\tR\fpresharedKey\x12\x1f\n" +
+	"\vallowed_ips\x18\x03 \x03(\tR\n" +
+	"allowedIps\"\xaf\x02\n" +
 	"\x05Proxy\x12$\n" +
 	"\x05vmess\x18\x01 \x01(\v2\x0e.service.VmessR\x05vmess\x12$\n" +
 	"\x05vless\x18\x02 \x01(\v2\x0e.service.VlessR\x05vless\x12'\n" +
 	"\x06trojan\x18\x03 \x01(\v2\x0f.service.TrojanR\x06trojan\x126\n" +
-	"\vshadowsocks\x18\x04 \x01(\v2\x14.service.ShadowsocksR\vshadowsocks\"b\n" +
+	"\vshadowsocks\x18\x04 \x01(\v2\x14.service.ShadowsocksR\vshadowsocks\x12$\n" +
+	"\x05socks\x18\x05 \x01(\v2\x0e.service.SocksR\x05socks\x12!\n" +
+	"\x04http\x18\x06 \x01(\v2\r.service.HttpR\x04http\x120\n" +
+	"\twireguard\x18\a \x01(\v2\x12.service.WireguardR\twireguard\"\xf0\x01\n" +
 	"\x04User\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12(\n" +
 	"\aproxies\x18\x02 \x01(\v2\x0e.service.ProxyR\aproxies\x12\x1a\n" +
-	"\binbounds\x18\x03 \x03(\tR\binbounds\",\n" +
+	"\binbounds\x18\x03 \x03(\tR\binbounds\x12\x1d\n" +
+	"\n" +
+	"data_limit\x18\x04 \x01(\x04R\tdataLimit\x12!\n" +
+	"\fused_traffic\x18\x05 \x01(\x04R\vusedTraffic\x12\x1b\n" +
+	"\texpire_at\x18\x06 \x01(\x03R\bexpireAt\x12\x17\n" +
+	"\amax_ips\x18\a \x01(\rR\x06maxIps\x12\x14\n" +
+	"\x05level\x18\b \x01(\rR\x05level\",\n" +
 	"\x05Users\x12#\n" +
-	"\x05users\x18\x01 \x03(\v2\r.service.UserR\x05users*\x17\n" +
+	"\x05users\x18\x01 \x03(\v2\r.service.UserR\x05users\",\n" +
+	"\x12RemoveUsersRequest\x12\x16\n" +
+	"\x06emails\x18\x01 \x03(\tR\x06emails\"g\n" +
+	"\x13RemoveUsersResponse\x12\x18\n" +
+	"\aremoved\x18\x01 \x03(\tR\aremoved\x12\x18\n" +
+	"\aunknown\x18\x02 \x03(\tR\aunknown\x12\x1c\n" +
+	"\trestarted\x18\x03 \x01(\bR\trestarted\"#\n" +
+	"\vUserRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"c\n" +
+	"\vUserInbound\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12&\n" +
+	"\x0enormalizations\x18\x03 \x03(\tR\x0enormalizations\"j\n" +
+	"\n" +
+	"LoadedUser\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\rR\x05level\x120\n" +
+	"\binbounds\x18\x03 \x03(\v2\x14.service.UserInboundR\binbounds\"@\n" +
+	"\x13LoadedUsersResponse\x12)\n" +
+	"\x05users\x18\x01 \x03(\v2\x13.service.LoadedUserR\x05users\"\x8f\x02\n" +
+	"\x0eRestrictedUser\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x122\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\x1a.service.RestrictionReasonR\x06reason\x12#\n" +
+	"\rrestricted_at\x18\x03 \x01(\x03R\frestrictedAt\x12!\n" +
+	"\fused_traffic\x18\x04 \x01(\x04R\vusedTraffic\x12\x1d\n" +
+	"\n" +
+	"data_limit\x18\x05 \x01(\x04R\tdataLimit\x12\x1b\n" +
+	"\texpire_at\x18\x06 \x01(\x03R\bexpireAt\x12\x10\n" +
+	"\x03ips\x18\a \x03(\tR\x03ips\x12\x1d\n" +
+	"\n" +
+	"release_at\x18\b \x01(\x03R\treleaseAt\"H\n" +
+	"\x17RestrictedUsersResponse\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.service.RestrictedUserR\x05users\"\x9e\x01\n" +
+	"\x10EnforcementEvent\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x122\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\x1a.service.RestrictionReasonR\x06reason\x12\x1a\n" +
+	"\breleased\x18\x03 \x01(\bR\breleased\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\x03R\x04time\x12\x10\n" +
+	"\x03ips\x18\x05 \x03(\tR\x03ips\"0\n" +
+	"\x18EnforcementEventsRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\"N\n" +
+	"\x19EnforcementEventsResponse\x121\n" +
+	"\x06events\x18\x01 \x03(\v2\x19.service.EnforcementEventR\x06events\",\n" +
+	"\x12ExpirationsRequest\x12\x16\n" +
+	"\x06within\x18\x01 \x01(\x03R\x06within\"C\n" +
+	"\x0eUserExpiration\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1b\n" +
+	"\texpire_at\x18\x02 \x01(\x03R\bexpireAt\"D\n" +
+	"\x13ExpirationsResponse\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.service.UserExpirationR\x05users\"\xd3\x02\n" +
+	"\x06Policy\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\rR\x05level\x12!\n" +
+	"\thandshake\x18\x02 \x01(\rH\x00R\thandshake\x88\x01\x01\x12 \n" +
+	"\tconn_idle\x18\x03 \x01(\rH\x01R\bconnIdle\x88\x01\x01\x12$\n" +
+	"\vuplink_only\x18\x04 \x01(\rH\x02R\n" +
+	"uplinkOnly\x88\x01\x01\x12(\n" +
+	"\rdownlink_only\x18\x05 \x01(\rH\x03R\fdownlinkOnly\x88\x01\x01\x12$\n" +
+	"\vbuffer_size\x18\x06 \x01(\x05H\x04R\n" +
+	"bufferSize\x88\x01\x01\x12*\n" +
+	"\x11stats_user_online\x18\a \x01(\bR\x0fstatsUserOnlineB\f\n" +
+	"\n" +
+	"_handshakeB\f\n" +
+	"\n" +
+	"_conn_idleB\x0e\n" +
+	"\f_uplink_onlyB\x10\n" +
+	"\x0e_downlink_onlyB\x0e\n" +
+	"\f_buffer_size\"3\n" +
+	"\bPolicies\x12'\n" +
+	"\x06levels\x18\x01 \x03(\v2\x0f.service.PolicyR\x06levels\"\x99\x01\n" +
+	"\x11SyncUsersResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x01(\rR\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\rR\aremoved\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\rR\aupdated\x12\x1c\n" +
+	"\tunchanged\x18\x04 \x01(\rR\tunchanged\x12\x1c\n" +
+	"\trestarted\x18\x05 \x01(\bR\trestarted\"T\n" +
+	"\x03Ban\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x1a\n" +
+	"\bfailures\x18\x02 \x01(\rR\bfailures\x12!\n" +
+	"\fbanned_until\x18\x03 \x01(\x03R\vbannedUntil\"0\n" +
+	"\fBansResponse\x12 \n" +
+	"\x04bans\x18\x01 \x03(\v2\f.service.BanR\x04bans\"$\n" +
+	"\x10ClearBansRequest\x12\x10\n" +
+	"\x03ips\x18\x01 \x03(\tR\x03ips\"-\n" +
+	"\x11ClearBansResponse\x12\x18\n" +
+	"\acleared\x18\x01 \x01(\rR\acleared\"\xfa\x01\n" +
+	"\fAuditChanges\x12\x14\n" +
+	"\x05users\x18\x01 \x03(\tR\x05users\x12\x1f\n" +
+	"\vusers_added\x18\x02 \x01(\rR\n" +
+	"usersAdded\x12#\n" +
+	"\rusers_removed\x18\x03 \x01(\rR\fusersRemoved\x12#\n" +
+	"\rusers_updated\x18\x04 \x01(\rR\fusersUpdated\x12\x1f\n" +
+	"\vconfig_hash\x18\x05 \x01(\tR\n" +
+	"configHash\x12%\n" +
+	"\x0ecore_restarted\x18\x06 \x01(\bR\rcoreRestarted\x12!\n" +
+	"\fcore_stopped\x18\a \x01(\bR\vcoreStopped\"\xbe\x01\n" +
+	"\n" +
+	"AuditEntry\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06method\x18\x05 \x01(\tR\x06method\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\tR\x04code\x12/\n" +
+	"\achanges\x18\a \x01(\v2\x15.service.AuditChangesR\achanges\"k\n" +
+	"\x0fAuditLogRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\x03R\x05until\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\rR\x05limit\"A\n" +
+	"\x10AuditLogResponse\x12-\n" +
+	"\aentries\x18\x01 \x03(\v2\x13.service.AuditEntryR\aentries*\x17\n" +
 	"\vBackendType\x12\b\n" +
 	"\x04XRAY\x10\x00*_\n" +
 	"\bStatType\x12\r\n" +
@@ -1270,7 +3161,12 @@ const file_common_service_proto_rawDesc = "" +
 	"\bInbounds\x10\x02\x12\v\n" +
 	"\aInbound\x10\x03\x12\r\n" +
 	"\tUsersStat\x10\x04\x12\f\n" +
-	"\bUserStat\x10\x052\x9a\x05\n" +
+	"\bUserStat\x10\x05*b\n" +
+	"\x11RestrictionReason\x12\x15\n" +
+	"\x11ReasonUnspecified\x10\x00\x12\x14\n" +
+	"\x10DataLimitReached\x10\x01\x12\v\n" +
+	"\aExpired\x10\x02\x12\x13\n" +
+	"\x0fIpLimitExceeded\x10\x032\x91\v\n" +
 	"\vGateService\x126\n" +
 	"\x05Start\x12\x10.service.Backend\x1a\x19.service.BaseInfoResponse\"\x00\x12(\n" +
 	"\x04Stop\x12\x0e.service.Empty\x1a\x0e.service.Empty\"\x00\x12:\n" +
@@ -1281,8 +3177,19 @@ const file_common_service_proto_rawDesc = "" +
 	"\bGetStats\x12\x14.service.StatRequest\x1a\x15.service.StatResponse\"\x00\x12I\n" +
 	"\x12GetUserOnlineStats\x12\x14.service.StatRequest\x1a\x1b.service.OnlineStatResponse\"\x00\x12V\n" +
 	"\x18GetUserOnlineIpListStats\x12\x14.service.StatRequest\x1a\".service.StatsOnlineIpListResponse\"\x00\x12-\n" +
-	"\bSyncUser\x12\r.service.User\x1a\x0e.service.Empty\"\x00(\x01\x12-\n" +
-	"\tSyncUsers\x12\x0e.service.Users\x1a\x0e.service.Empty\"\x00B#Z!github.com/Rexa/Gate/commonb\x06proto3"
+	"\bSyncUser\x12\r.service.User\x1a\x0e.service.Empty\"\x00(\x01\x129\n" +
+	"\tSyncUsers\x12\x0e.service.Users\x1a\x1a.service.SyncUsersResponse\"\x00\x12J\n" +
+	"\vRemoveUsers\x12\x1b.service.RemoveUsersRequest\x1a\x1c.service.RemoveUsersResponse\"\x00\x12;\n" +
+	"\tListUsers\x12\x0e.service.Empty\x1a\x1c.service.LoadedUsersResponse\"\x00\x126\n" +
+	"\aGetUser\x12\x14.service.UserRequest\x1a\x13.service.LoadedUser\"\x00\x12H\n" +
+	"\x12GetRestrictedUsers\x12\x0e.service.Empty\x1a .service.RestrictedUsersResponse\"\x00\x12U\n" +
+	"\x16GetUpcomingExpirations\x12\x1b.service.ExpirationsRequest\x1a\x1c.service.ExpirationsResponse\"\x00\x12_\n" +
+	"\x14GetEnforcementEvents\x12!.service.EnforcementEventsRequest\x1a\".service.EnforcementEventsResponse\"\x00\x122\n" +
+	"\vGetPolicies\x12\x0e.service.Empty\x1a\x11.service.Policies\"\x00\x122\n" +
+	"\vSetPolicies\x12\x11.service.Policies\x1a\x0e.service.Empty\"\x00\x122\n" +
+	"\aGetBans\x12\x0e.service.Empty\x1a\x15.service.BansResponse\"\x00\x12D\n" +
+	"\tClearBans\x12\x19.service.ClearBansRequest\x1a\x1a.service.ClearBansResponse\"\x00\x12D\n" +
+	"\vGetAuditLog\x12\x18.service.AuditLogRequest\x1a\x19.service.AuditLogResponse\"\x00B!Z\x1fgithub.com/rexa-dev/Gate/commonb\x06proto3"
 
 var (
 	file_common_service_proto_rawDescOnce sync.Once
@@ -1296,70 +3203,135 @@ func file_common_service_proto_rawDescGZIP() []byte {
 	return file_common_service_proto_rawDescData
 }
 
-var file_common_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_common_service_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_common_service_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_common_service_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_common_service_proto_goTypes = []any{
 	(BackendType)(0),                  // 0: service.BackendType
 	(StatType)(0),                     // 1: service.StatType
-	(*Empty)(nil),                     // 2: service.Empty
-	(*BaseInfoResponse)(nil),          // 3: service.BaseInfoResponse
-	(*Backend)(nil),                   // 4: service.Backend
-	(*Log)(nil),                       // 5: service.Log
-	(*Stat)(nil),                      // 6: service.Stat
-	(*StatResponse)(nil),              // 7: service.StatResponse
-	(*StatRequest)(nil),               // 8: service.StatRequest
-	(*OnlineStatResponse)(nil),        // 9: service.OnlineStatResponse
-	(*StatsOnlineIpListResponse)(nil), // 10: service.StatsOnlineIpListResponse
-	(*BackendStatsResponse)(nil),      // 11: service.BackendStatsResponse
-	(*SystemStatsResponse)(nil),       // 12: service.SystemStatsResponse
-	(*Vmess)(nil),                     // 13: service.Vmess
-	(*Vless)(nil),                     // 14: service.Vless
-	(*Trojan)(nil),                    // 15: service.Trojan
-	(*Shadowsocks)(nil),               // 16: service.Shadowsocks
-	(*Proxy)(nil),                     // 17: service.Proxy
-	(*User)(nil),                      // 18: service.User
-	(*Users)(nil),                     // 19: service.Users
-	nil,                               // 20: service.StatsOnlineIpListResponse.IpsEntry
+	(RestrictionReason)(0),            // 2: service.RestrictionReason
+	(*Empty)(nil),                     // 3: service.Empty
+	(*BaseInfoResponse)(nil),          // 4: service.BaseInfoResponse
+	(*Backend)(nil),                   // 5: service.Backend
+	(*Log)(nil),                       // 6: service.Log
+	(*Stat)(nil),                      // 7: service.Stat
+	(*StatResponse)(nil),              // 8: service.StatResponse
+	(*StatRequest)(nil),               // 9: service.StatRequest
+	(*OnlineStatResponse)(nil),        // 10: service.OnlineStatResponse
+	(*StatsOnlineIpListResponse)(nil), // 11: service.StatsOnlineIpListResponse
+	(*BackendStatsResponse)(nil),      // 12: service.BackendStatsResponse
+	(*SystemStatsResponse)(nil),       // 13: service.SystemStatsResponse
+	(*Vmess)(nil),                     // 14: service.Vmess
+	(*Vless)(nil),                     // 15: service.Vless
+	(*Trojan)(nil),                    // 16: service.Trojan
+	(*Shadowsocks)(nil),               // 17: service.Shadowsocks
+	(*Socks)(nil),                     // 18: service.Socks
+	(*Http)(nil),                      // 19: service.Http
+	(*Wireguard)(nil),                 // 20: service.Wireguard
+	(*Proxy)(nil),                     // 21: service.Proxy
+	(*User)(nil),                      // 22: service.User
+	(*Users)(nil),                     // 23: service.Users
+	(*RemoveUsersRequest)(nil),        // 24: service.RemoveUsersRequest
+	(*RemoveUsersResponse)(nil),       // 25: service.RemoveUsersResponse
+	(*UserRequest)(nil),               // 26: service.UserRequest
+	(*UserInbound)(nil),               // 27: service.UserInbound
+	(*LoadedUser)(nil),                // 28: service.LoadedUser
+	(*LoadedUsersResponse)(nil),       // 29: service.LoadedUsersResponse
+	(*RestrictedUser)(nil),            // 30: service.RestrictedUser
+	(*RestrictedUsersResponse)(nil),   // 31: service.RestrictedUsersResponse
+	(*EnforcementEvent)(nil),          // 32: service.EnforcementEvent
+	(*EnforcementEventsRequest)(nil),  // 33: service.EnforcementEventsRequest
+	(*EnforcementEventsResponse)(nil), // 34: service.EnforcementEventsResponse
+	(*ExpirationsRequest)(nil),        // 35: service.ExpirationsRequest
+	(*UserExpiration)(nil),            // 36: service.UserExpiration
+	(*ExpirationsResponse)(nil),       // 37: service.ExpirationsResponse
+	(*Policy)(nil),                    // 38: service.Policy
+	(*Policies)(nil),                  // 39: service.Policies
+	(*SyncUsersResponse)(nil),         // 40: service.SyncUsersResponse
+	(*Ban)(nil),                       // 41: service.Ban
+	(*BansResponse)(nil),              // 42: service.BansResponse
+	(*ClearBansRequest)(nil),          // 43: service.ClearBansRequest
+	(*ClearBansResponse)(nil),         // 44: service.ClearBansResponse
+	(*AuditChanges)(nil),              // 45: service.AuditChanges
+	(*AuditEntry)(nil),                // 46: service.AuditEntry
+	(*AuditLogRequest)(nil),           // 47: service.AuditLogRequest
+	(*AuditLogResponse)(nil),          // 48: service.AuditLogResponse
+	nil,                               // 49: service.StatsOnlineIpListResponse.IpsEntry
 }
 var file_common_service_proto_depIdxs = []int32{
 	0,  // 0: service.Backend.type:type_name -> service.BackendType
-	18, // 1: service.Backend.users:type_name -> service.User
-	6,  // 2: service.StatResponse.stats:type_name -> service.Stat
+	22, // 1: service.Backend.users:type_name -> service.User
+	7,  // 2: service.StatResponse.stats:type_name -> service.Stat
 	1,  // 3: service.StatRequest.type:type_name -> service.StatType
-	20, // 4: service.StatsOnlineIpListResponse.ips:type_name -> service.StatsOnlineIpListResponse.IpsEntry
-	13, // 5: service.Proxy.vmess:type_name -> service.Vmess
-	14, // 6: service.Proxy.vless:type_name -> service.Vless
-	15, // 7: service.Proxy.trojan:type_name -> service.Trojan
-	16, // 8: service.Proxy.shadowsocks:type_name -> service.Shadowsocks
-	17, // 9: service.User.proxies:type_name -> service.Proxy
-	18, // 10: service.Users.users:type_name -> service.User
-	4,  // 11: service.GateService.Start:input_type -> service.Backend
-	2,  // 12: service.GateService.Stop:input_type -> service.Empty
-	2,  // 13: service.GateService.GetBaseInfo:input_type -> service.Empty
-	2,  // 14: service.GateService.GetLogs:input_type -> service.Empty
-	2,  // 15: service.GateService.GetSystemStats:input_type -> service.Empty
-	2,  // 16: service.GateService.GetBackendStats:input_type -> service.Empty
-	8,  // 17: service.GateService.GetStats:input_type -> service.StatRequest
-	8,  // 18: service.GateService.GetUserOnlineStats:input_type -> service.StatRequest
-	8,  // 19: service.GateService.GetUserOnlineIpListStats:input_type -> service.StatRequest
-	18, // 20: service.GateService.SyncUser:input_type -> service.User
-	19, // 21: service.GateService.SyncUsers:input_type -> service.Users
-	3,  // 22: service.GateService.Start:output_type -> service.BaseInfoResponse
-	2,  // 23: service.GateService.Stop:output_type -> service.Empty
-	3,  // 24: service.GateService.GetBaseInfo:output_type -> service.BaseInfoResponse
-	5,  // 25: service.GateService.GetLogs:output_type -> service.Log
-	12, // 26: service.GateService.GetSystemStats:output_type -> service.SystemStatsResponse
-	11, // 27: service.GateService.GetBackendStats:output_type -> service.BackendStatsResponse
-	7,  // 28: service.GateService.GetStats:output_type -> service.StatResponse
-	9,  // 29: service.GateService.GetUserOnlineStats:output_type -> service.OnlineStatResponse
-	10, // 30: service.GateService.GetUserOnlineIpListStats:output_type -> service.StatsOnlineIpListResponse
-	2,  // 31: service.GateService.SyncUser:output_type -> service.Empty
-	2,  // 32: service.GateService.SyncUsers:output_type -> service.Empty
-	22, // [22:33] is the sub-list for method output_type
-	11, // [11:22] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	49, // 4: service.StatsOnlineIpListResponse.ips:type_name -> service.StatsOnlineIpListResponse.IpsEntry
+	14, // 5: service.Proxy.vmess:type_name -> service.Vmess
+	15, // 6: service.Proxy.vless:type_name -> service.Vless
+	16, // 7: service.Proxy.trojan:type_name -> service.Trojan
+	17, // 8: service.Proxy.shadowsocks:type_name -> service.Shadowsocks
+	18, // 9: service.Proxy.socks:type_name -> service.Socks
+	19, // 10: service.Proxy.http:type_name -> service.Http
+	20, // 11: service.Proxy.wireguard:type_name -> service.Wireguard
+	21, // 12: service.User.proxies:type_name -> service.Proxy
+	22, // 13: service.Users.users:type_name -> service.User
+	27, // 14: service.LoadedUser.inbounds:type_name -> service.UserInbound
+	28, // 15: service.LoadedUsersResponse.users:type_name -> service.LoadedUser
+	2,  // 16: service.RestrictedUser.reason:type_name -> service.RestrictionReason
+	30, // 17: service.RestrictedUsersResponse.users:type_name -> service.RestrictedUser
+	2,  // 18: service.EnforcementEvent.reason:type_name -> service.RestrictionReason
+	32, // 19: service.EnforcementEventsResponse.events:type_name -> service.EnforcementEvent
+	36, // 20: service.ExpirationsResponse.users:type_name -> service.UserExpiration
+	38, // 21: service.Policies.levels:type_name -> service.Policy
+	41, // 22: service.BansResponse.bans:type_name -> service.Ban
+	45, // 23: service.AuditEntry.changes:type_name -> service.AuditChanges
+	46, // 24: service.AuditLogResponse.entries:type_name -> service.AuditEntry
+	5,  // 25: service.GateService.Start:input_type -> service.Backend
+	3,  // 26: service.GateService.Stop:input_type -> service.Empty
+	3,  // 27: service.GateService.GetBaseInfo:input_type -> service.Empty
+	3,  // 28: service.GateService.GetLogs:input_type -> service.Empty
+	3,  // 29: service.GateService.GetSystemStats:input_type -> service.Empty
+	3,  // 30: service.GateService.GetBackendStats:input_type -> service.Empty
+	9,  // 31: service.GateService.GetStats:input_type -> service.StatRequest
+	9,  // 32: service.GateService.GetUserOnlineStats:input_type -> service.StatRequest
+	9,  // 33: service.GateService.GetUserOnlineIpListStats:input_type -> service.StatRequest
+	22, // 34: service.GateService.SyncUser:input_type -> service.User
+	23, // 35: service.GateService.SyncUsers:input_type -> service.Users
+	24, // 36: service.GateService.RemoveUsers:input_type -> service.RemoveUsersRequest
+	3,  // 37: service.GateService.ListUsers:input_type -> service.Empty
+	26, // 38: service.GateService.GetUser:input_type -> service.UserRequest
+	3,  // 39: service.GateService.GetRestrictedUsers:input_type -> service.Empty
+	35, // 40: service.GateService.GetUpcomingExpirations:input_type -> service.ExpirationsRequest
+	33, // 41: service.GateService.GetEnforcementEvents:input_type -> service.EnforcementEventsRequest
+	3,  // 42: service.GateService.GetPolicies:input_type -> service.Empty
+	39, // 43: service.GateService.SetPolicies:input_type -> service.Policies
+	3,  // 44: service.GateService.GetBans:input_type -> service.Empty
+	43, // 45: service.GateService.ClearBans:input_type -> service.ClearBansRequest
+	47, // 46: service.GateService.GetAuditLog:input_type -> service.AuditLogRequest
+	4,  // 47: service.GateService.Start:output_type -> service.BaseInfoResponse
+	3,  // 48: service.GateService.Stop:output_type -> service.Empty
+	4,  // 49: service.GateService.GetBaseInfo:output_type -> service.BaseInfoResponse
+	6,  // 50: service.GateService.GetLogs:output_type -> service.Log
+	13, // 51: service.GateService.GetSystemStats:output_type -> service.SystemStatsResponse
+	12, // 52: service.GateService.GetBackendStats:output_type -> service.BackendStatsResponse
+	8,  // 53: service.GateService.GetStats:output_type -> service.StatResponse
+	10, // 54: service.GateService.GetUserOnlineStats:output_type -> service.OnlineStatResponse
+	11, // 55: service.GateService.GetUserOnlineIpListStats:output_type -> service.StatsOnlineIpListResponse
+	3,  // 56: service.GateService.SyncUser:output_type -> service.Empty
+	40, // 57: service.GateService.SyncUsers:output_type -> service.SyncUsersResponse
+	25, // 58: service.GateService.RemoveUsers:output_type -> service.RemoveUsersResponse
+	29, // 59: service.GateService.ListUsers:output_type -> service.LoadedUsersResponse
+	28, // 60: service.GateService.GetUser:output_type -> service.LoadedUser
+	31, // 61: service.GateService.GetRestrictedUsers:output_type -> service.RestrictedUsersResponse
+	37, // 62: service.GateService.GetUpcomingExpirations:output_type -> service.ExpirationsResponse
+	34, // 63: service.GateService.GetEnforcementEvents:output_type -> service.EnforcementEventsResponse
+	39, // 64: service.GateService.GetPolicies:output_type -> service.Policies
+	3,  // 65: service.GateService.SetPolicies:output_type -> service.Empty
+	42, // 66: service.GateService.GetBans:output_type -> service.BansResponse
+	44, // 67: service.GateService.ClearBans:output_type -> service.ClearBansResponse
+	48, // 68: service.GateService.GetAuditLog:output_type -> service.AuditLogResponse
+	47, // [47:69] is the sub-list for method output_type
+	25, // [25:47] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_common_service_proto_init() }
@@ -1367,13 +3339,14 @@ func file_common_service_proto_init() {
 	if File_common_service_proto != nil {
 		return
 	}
+	file_common_service_proto_msgTypes[35].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_service_proto_rawDesc), len(file_common_service_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   19,
+			NumEnums:      3,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
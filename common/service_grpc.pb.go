@@ -30,6 +30,17 @@ const (
 	GateService_GetUserOnlineIpListStats_FullMethodName = "/service.GateService/GetUserOnlineIpListStats"
 	GateService_SyncUser_FullMethodName                 = "/service.GateService/SyncUser"
 	GateService_SyncUsers_FullMethodName                = "/service.GateService/SyncUsers"
+	GateService_RemoveUsers_FullMethodName              = "/service.GateService/RemoveUsers"
+	GateService_ListUsers_FullMethodName                = "/service.GateService/ListUsers"
+	GateService_GetUser_FullMethodName                  = "/service.GateService/GetUser"
+	GateService_GetRestrictedUsers_FullMethodName       = "/service.GateService/GetRestrictedUsers"
+	GateService_GetUpcomingExpirations_FullMethodName   = "/service.GateService/GetUpcomingExpirations"
+	GateService_GetEnforcementEvents_FullMethodName     = "/service.GateService/GetEnforcementEvents"
+	GateService_GetPolicies_FullMethodName              = "/service.GateService/GetPolicies"
+	GateService_SetPolicies_FullMethodName              = "/service.GateService/SetPolicies"
+	GateService_GetBans_FullMethodName                  = "/service.GateService/GetBans"
+	GateService_ClearBans_FullMethodName                = "/service.GateService/ClearBans"
+	GateService_GetAuditLog_FullMethodName              = "/service.GateService/GetAuditLog"
 )
 
 // GateServiceClient is the client API for GateService service.
@@ -48,18 +59,29 @@ type GateServiceClient interface {
 	GetUserOnlineStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*OnlineStatResponse, error)
 	GetUserOnlineIpListStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatsOnlineIpListResponse, error)
 	SyncUser(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[User, Empty], error)
-	SyncUsers(ctx context.Context, in *Users, opts ...grpc.CallOption) (*Empty, error)
-}
-
-type GateServiceClient struct {
+	SyncUsers(ctx context.Context, in *Users, opts ...grpc.CallOption) (*SyncUsersResponse, error)
+	RemoveUsers(ctx context.Context, in *RemoveUsersRequest, opts ...grpc.CallOption) (*RemoveUsersResponse, error)
+	ListUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LoadedUsersResponse, error)
+	GetUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*LoadedUser, error)
+	GetRestrictedUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RestrictedUsersResponse, error)
+	GetUpcomingExpirations(ctx context.Context, in *ExpirationsRequest, opts ...grpc.CallOption) (*ExpirationsResponse, error)
+	GetEnforcementEvents(ctx context.Context, in *EnforcementEventsRequest, opts ...grpc.CallOption) (*EnforcementEventsResponse, error)
+	GetPolicies(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Policies, error)
+	SetPolicies(ctx context.Context, in *Policies, opts ...grpc.CallOption) (*Empty, error)
+	GetBans(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BansResponse, error)
+	ClearBans(ctx context.Context, in *ClearBansRequest, opts ...grpc.CallOption) (*ClearBansResponse, error)
+	GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
+}
+
+type gateServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
 func NewGateServiceClient(cc grpc.ClientConnInterface) GateServiceClient {
-	return &GateServiceClient{cc}
+	return &gateServiceClient{cc}
 }
 
-func (c *GateServiceClient) Start(ctx context.Context, in *Backend, opts ...grpc.CallOption) (*BaseInfoResponse, error) {
+func (c *gateServiceClient) Start(ctx context.Context, in *Backend, opts ...grpc.CallOption) (*BaseInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BaseInfoResponse)
 	err := c.cc.Invoke(ctx, GateService_Start_FullMethodName, in, out, cOpts...)
@@ -69,7 +91,7 @@ func (c *GateServiceClient) Start(ctx context.Context, in *Backend, opts ...grpc
 	return out, nil
 }
 
-func (c *GateServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *gateServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, GateService_Stop_FullMethodName, in, out, cOpts...)
@@ -79,7 +101,7 @@ func (c *GateServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.Ca
 	return out, nil
 }
 
-func (c *GateServiceClient) GetBaseInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BaseInfoResponse, error) {
+func (c *gateServiceClient) GetBaseInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BaseInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BaseInfoResponse)
 	err := c.cc.Invoke(ctx, GateService_GetBaseInfo_FullMethodName, in, out, cOpts...)
@@ -89,7 +111,7 @@ func (c *GateServiceClient) GetBaseInfo(ctx context.Context, in *Empty, opts ...
 	return out, nil
 }
 
-func (c *GateServiceClient) GetLogs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Log], error) {
+func (c *gateServiceClient) GetLogs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Log], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &GateService_ServiceDesc.Streams[0], GateService_GetLogs_FullMethodName, cOpts...)
 	if err != nil {
@@ -108,7 +130,7 @@ func (c *GateServiceClient) GetLogs(ctx context.Context, in *Empty, opts ...grpc
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GateService_GetLogsClient = grpc.ServerStreamingClient[Log]
 
-func (c *GateServiceClient) GetSystemStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SystemStatsResponse, error) {
+func (c *gateServiceClient) GetSystemStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SystemStatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SystemStatsResponse)
 	err := c.cc.Invoke(ctx, GateService_GetSystemStats_FullMethodName, in, out, cOpts...)
@@ -118,7 +140,7 @@ func (c *GateServiceClient) GetSystemStats(ctx context.Context, in *Empty, opts
 	return out, nil
 }
 
-func (c *GateServiceClient) GetBackendStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BackendStatsResponse, error) {
+func (c *gateServiceClient) GetBackendStats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BackendStatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BackendStatsResponse)
 	err := c.cc.Invoke(ctx, GateService_GetBackendStats_FullMethodName, in, out, cOpts...)
@@ -128,7 +150,7 @@ func (c *GateServiceClient) GetBackendStats(ctx context.Context, in *Empty, opts
 	return out, nil
 }
 
-func (c *GateServiceClient) GetStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error) {
+func (c *gateServiceClient) GetStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatResponse)
 	err := c.cc.Invoke(ctx, GateService_GetStats_FullMethodName, in, out, cOpts...)
@@ -138,7 +160,7 @@ func (c *GateServiceClient) GetStats(ctx context.Context, in *StatRequest, opts
 	return out, nil
 }
 
-func (c *GateServiceClient) GetUserOnlineStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*OnlineStatResponse, error) {
+func (c *gateServiceClient) GetUserOnlineStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*OnlineStatResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(OnlineStatResponse)
 	err := c.cc.Invoke(ctx, GateService_GetUserOnlineStats_FullMethodName, in, out, cOpts...)
@@ -148,7 +170,7 @@ func (c *GateServiceClient) GetUserOnlineStats(ctx context.Context, in *StatRequ
 	return out, nil
 }
 
-func (c *GateServiceClient) GetUserOnlineIpListStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatsOnlineIpListResponse, error) {
+func (c *gateServiceClient) GetUserOnlineIpListStats(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatsOnlineIpListResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsOnlineIpListResponse)
 	err := c.cc.Invoke(ctx, GateService_GetUserOnlineIpListStats_FullMethodName, in, out, cOpts...)
@@ -158,7 +180,7 @@ func (c *GateServiceClient) GetUserOnlineIpListStats(ctx context.Context, in *St
 	return out, nil
 }
 
-func (c *GateServiceClient) SyncUser(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[User, Empty], error) {
+func (c *gateServiceClient) SyncUser(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[User, Empty], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &GateService_ServiceDesc.Streams[1], GateService_SyncUser_FullMethodName, cOpts...)
 	if err != nil {
@@ -171,9 +193,9 @@ func (c *GateServiceClient) SyncUser(ctx context.Context, opts ...grpc.CallOptio
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GateService_SyncUserClient = grpc.ClientStreamingClient[User, Empty]
 
-func (c *GateServiceClient) SyncUsers(ctx context.Context, in *Users, opts ...grpc.CallOption) (*Empty, error) {
+func (c *gateServiceClient) SyncUsers(ctx context.Context, in *Users, opts ...grpc.CallOption) (*SyncUsersResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Empty)
+	out := new(SyncUsersResponse)
 	err := c.cc.Invoke(ctx, GateService_SyncUsers_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -181,6 +203,116 @@ func (c *GateServiceClient) SyncUsers(ctx context.Context, in *Users, opts ...gr
 	return out, nil
 }
 
+func (c *gateServiceClient) RemoveUsers(ctx context.Context, in *RemoveUsersRequest, opts ...grpc.CallOption) (*RemoveUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveUsersResponse)
+	err := c.cc.Invoke(ctx, GateService_RemoveUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) ListUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*LoadedUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoadedUsersResponse)
+	err := c.cc.Invoke(ctx, GateService_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*LoadedUser, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoadedUser)
+	err := c.cc.Invoke(ctx, GateService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetRestrictedUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RestrictedUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestrictedUsersResponse)
+	err := c.cc.Invoke(ctx, GateService_GetRestrictedUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetUpcomingExpirations(ctx context.Context, in *ExpirationsRequest, opts ...grpc.CallOption) (*ExpirationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpirationsResponse)
+	err := c.cc.Invoke(ctx, GateService_GetUpcomingExpirations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetEnforcementEvents(ctx context.Context, in *EnforcementEventsRequest, opts ...grpc.CallOption) (*EnforcementEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnforcementEventsResponse)
+	err := c.cc.Invoke(ctx, GateService_GetEnforcementEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetPolicies(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Policies, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Policies)
+	err := c.cc.Invoke(ctx, GateService_GetPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) SetPolicies(ctx context.Context, in *Policies, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, GateService_SetPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetBans(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*BansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BansResponse)
+	err := c.cc.Invoke(ctx, GateService_GetBans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) ClearBans(ctx context.Context, in *ClearBansRequest, opts ...grpc.CallOption) (*ClearBansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearBansResponse)
+	err := c.cc.Invoke(ctx, GateService_ClearBans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gateServiceClient) GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditLogResponse)
+	err := c.cc.Invoke(ctx, GateService_GetAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GateServiceServer is the server API for GateService service.
 // All implementations must embed UnimplementedGateServiceServer
 // for forward compatibility.
@@ -197,7 +329,18 @@ type GateServiceServer interface {
 	GetUserOnlineStats(context.Context, *StatRequest) (*OnlineStatResponse, error)
 	GetUserOnlineIpListStats(context.Context, *StatRequest) (*StatsOnlineIpListResponse, error)
 	SyncUser(grpc.ClientStreamingServer[User, Empty]) error
-	SyncUsers(context.Context, *Users) (*Empty, error)
+	SyncUsers(context.Context, *Users) (*SyncUsersResponse, error)
+	RemoveUsers(context.Context, *RemoveUsersRequest) (*RemoveUsersResponse, error)
+	ListUsers(context.Context, *Empty) (*LoadedUsersResponse, error)
+	GetUser(context.Context, *UserRequest) (*LoadedUser, error)
+	GetRestrictedUsers(context.Context, *Empty) (*RestrictedUsersResponse, error)
+	GetUpcomingExpirations(context.Context, *ExpirationsRequest) (*ExpirationsResponse, error)
+	GetEnforcementEvents(context.Context, *EnforcementEventsRequest) (*EnforcementEventsResponse, error)
+	GetPolicies(context.Context, *Empty) (*Policies, error)
+	SetPolicies(context.Context, *Policies) (*Empty, error)
+	GetBans(context.Context, *Empty) (*BansResponse, error)
+	ClearBans(context.Context, *ClearBansRequest) (*ClearBansResponse, error)
+	GetAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
 	mustEmbedUnimplementedGateServiceServer()
 }
 
@@ -238,9 +381,42 @@ func (UnimplementedGateServiceServer) GetUserOnlineIpListStats(context.Context,
 func (UnimplementedGateServiceServer) SyncUser(grpc.ClientStreamingServer[User, Empty]) error {
 	return status.Errorf(codes.Unimplemented, "method SyncUser not implemented")
 }
-func (UnimplementedGateServiceServer) SyncUsers(context.Context, *Users) (*Empty, error) {
+func (UnimplementedGateServiceServer) SyncUsers(context.Context, *Users) (*SyncUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SyncUsers not implemented")
 }
+func (UnimplementedGateServiceServer) RemoveUsers(context.Context, *RemoveUsersRequest) (*RemoveUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveUsers not implemented")
+}
+func (UnimplementedGateServiceServer) ListUsers(context.Context, *Empty) (*LoadedUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedGateServiceServer) GetUser(context.Context, *UserRequest) (*LoadedUser, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedGateServiceServer) GetRestrictedUsers(context.Context, *Empty) (*RestrictedUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRestrictedUsers not implemented")
+}
+func (UnimplementedGateServiceServer) GetUpcomingExpirations(context.Context, *ExpirationsRequest) (*ExpirationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUpcomingExpirations not implemented")
+}
+func (UnimplementedGateServiceServer) GetEnforcementEvents(context.Context, *EnforcementEventsRequest) (*EnforcementEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEnforcementEvents not implemented")
+}
+func (UnimplementedGateServiceServer) GetPolicies(context.Context, *Empty) (*Policies, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicies not implemented")
+}
+func (UnimplementedGateServiceServer) SetPolicies(context.Context, *Policies) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPolicies not implemented")
+}
+func (UnimplementedGateServiceServer) GetBans(context.Context, *Empty) (*BansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBans not implemented")
+}
+func (UnimplementedGateServiceServer) ClearBans(context.Context, *ClearBansRequest) (*ClearBansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearBans not implemented")
+}
+func (UnimplementedGateServiceServer) GetAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
 func (UnimplementedGateServiceServer) mustEmbedUnimplementedGateServiceServer() {}
 func (UnimplementedGateServiceServer) testEmbeddedByValue()                     {}
 
@@ -442,6 +618,204 @@ func _GateService_SyncUsers_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GateService_RemoveUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).RemoveUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_RemoveUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).RemoveUsers(ctx, req.(*RemoveUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).ListUsers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetUser(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetRestrictedUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetRestrictedUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetRestrictedUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetRestrictedUsers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetUpcomingExpirations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpirationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetUpcomingExpirations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetUpcomingExpirations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetUpcomingExpirations(ctx, req.(*ExpirationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetEnforcementEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnforcementEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetEnforcementEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetEnforcementEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetEnforcementEvents(ctx, req.(*EnforcementEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetPolicies(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_SetPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Policies)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).SetPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_SetPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).SetPolicies(ctx, req.(*Policies))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetBans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetBans(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_ClearBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearBansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).ClearBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_ClearBans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).ClearBans(ctx, req.(*ClearBansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GateService_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GateServiceServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GateService_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GateServiceServer).GetAuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GateService_ServiceDesc is the grpc.ServiceDesc for GateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -485,6 +859,50 @@ var GateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SyncUsers",
 			Handler:    _GateService_SyncUsers_Handler,
 		},
+		{
+			MethodName: "RemoveUsers",
+			Handler:    _GateService_RemoveUsers_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _GateService_ListUsers_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _GateService_GetUser_Handler,
+		},
+		{
+			MethodName: "GetRestrictedUsers",
+			Handler:    _GateService_GetRestrictedUsers_Handler,
+		},
+		{
+			MethodName: "GetUpcomingExpirations",
+			Handler:    _GateService_GetUpcomingExpirations_Handler,
+		},
+		{
+			MethodName: "GetEnforcementEvents",
+			Handler:    _GateService_GetEnforcementEvents_Handler,
+		},
+		{
+			MethodName: "GetPolicies",
+			Handler:    _GateService_GetPolicies_Handler,
+		},
+		{
+			MethodName: "SetPolicies",
+			Handler:    _GateService_SetPolicies_Handler,
+		},
+		{
+			MethodName: "GetBans",
+			Handler:    _GateService_GetBans_Handler,
+		},
+		{
+			MethodName: "ClearBans",
+			Handler:    _GateService_ClearBans_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _GateService_GetAuditLog_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+type Scope string
+
+const (
+	ScopeStats     Scope = "stats"
+	ScopeLogs      Scope = "logs"
+	ScopeUsers     Scope = "users"
+	ScopeLifecycle Scope = "lifecycle"
+)
+
+const (
+	defaultKeyName  = "default"
+	previousKeyName = "previous"
+)
+
+var AllScopes = []Scope{ScopeStats, ScopeLogs, ScopeUsers, ScopeLifecycle}
+
+// roles are shorthands for common scope sets in the key file.
+var roles = map[string][]Scope{
+	"admin":     AllScopes,
+	"read-only": {ScopeStats, ScopeLogs},
+}
+
+type ApiKey struct {
+	Name   string    `json:"name"`
+	Key    uuid.UUID `json:"key"`
+	Role   string    `json:"role,omitempty"`
+	Scopes []Scope   `json:"scopes,omitempty"`
+	// CertFingerprints and CertSubjects bind the key to client certificates,
+	// a key with neither is accepted with any certificate.
+	CertFingerprints []string `json:"cert_fingerprints,omitempty"`
+	CertSubjects     []string `json:"cert_subjects,omitempty"`
+}
+
+func (k *ApiKey) Allows(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// AllowsCert reports whether the key may be used with the given client certificate,
+// cert is nil when the client sent none. Fingerprints are the hex encoded sha256 of the certificate.
+func (k *ApiKey) AllowsCert(cert *x509.Certificate) bool {
+	if len(k.CertFingerprints) == 0 && len(k.CertSubjects) == 0 {
+		return true
+	}
+	if cert == nil {
+		return false
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	return slices.Contains(k.CertFingerprints, hex.EncodeToString(sum[:])) ||
+		slices.Contains(k.CertSubjects, cert.Subject.CommonName) ||
+		slices.Contains(k.CertSubjects, cert.Subject.String())
+}
+
+// LoadApiKeys reads a json list of named keys, each key gets the scopes of its role plus the listed ones.
+func LoadApiKeys(path string) ([]*ApiKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*ApiKey
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+
+	// signed requests identify the key by name
+	names := map[string]bool{defaultKeyName: true, previousKeyName: true}
+	for _, key := range keys {
+		if key.Key == uuid.Nil {
+			return nil, fmt.Errorf("api key %q has no key", key.Name)
+		}
+		if names[key.Name] {
+			return nil, fmt.Errorf("api key name %q is reserved or used twice", key.Name)
+		}
+		names[key.Name] = true
+
+		if key.Role != "" {
+			scopes, ok := roles[key.Role]
+			if !ok {
+				return nil, fmt.Errorf("api key %q has unknown role %q", key.Name, key.Role)
+			}
+			key.Scopes = append(key.Scopes, scopes...)
+		}
+
+		for _, scope := range key.Scopes {
+			if !slices.Contains(AllScopes, scope) {
+				return nil, fmt.Errorf("api key %q has unknown scope %q", key.Name, scope)
+			}
+		}
+		slices.Sort(key.Scopes)
+		key.Scopes = slices.Compact(key.Scopes)
+
+		for i, fingerprint := range key.CertFingerprints {
+			key.CertFingerprints[i] = normalizeFingerprint(fingerprint)
+		}
+	}
+
+	return keys, nil
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// parseCertBinding splits a comma separated list of sha256 fingerprints and certificate subjects,
+// an item of 64 hex digits is taken as a fingerprint.
+func parseCertBinding(value string) (fingerprints, subjects []string) {
+	for _, item := range splitList(value) {
+		if fingerprint := normalizeFingerprint(item); len(fingerprint) == 2*sha256.Size {
+			if _, err := hex.DecodeString(fingerprint); err == nil {
+				fingerprints = append(fingerprints, fingerprint)
+				continue
+			}
+		}
+		subjects = append(subjects, item)
+	}
+	return fingerprints, subjects
+}
+
+// ReloadApiKeys reads API_KEY, API_KEY_CERT and API_KEYS_FILE again, the env file takes precedence over the environment.
+// The previous API_KEY is still accepted for ApiKeyGracePeriod seconds.
+func (c *Config) ReloadApiKeys() error {
+	env, _ := godotenv.Read()
+	lookup := func(name string) string {
+		if value, ok := env[name]; ok {
+			return value
+		}
+		return GetEnv(name, "")
+	}
+
+	key, err := uuid.Parse(lookup("API_KEY"))
+	if err != nil {
+		return fmt.Errorf("failed to parse API_KEY: %w", err)
+	}
+
+	var keys []*ApiKey
+	if apiKeysFile := lookup("API_KEYS_FILE"); apiKeysFile != "" {
+		if keys, err = LoadApiKeys(apiKeysFile); err != nil {
+			return fmt.Errorf("failed to load api keys file: %w", err)
+		}
+	}
+
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+
+	if key != c.ApiKey && c.ApiKeyGracePeriod > 0 {
+		c.previousApiKey = c.ApiKey
+		c.previousApiKeyUntil = time.Now().Add(time.Duration(c.ApiKeyGracePeriod) * time.Second)
+	}
+	c.ApiKey = key
+	c.ApiKeys = keys
+	c.apiKeyCertFingerprints, c.apiKeyCertSubjects = parseCertBinding(lookup("API_KEY_CERT"))
+	return nil
+}
+
+// LookupApiKey finds the key a request was made with, API_KEY always has every scope.
+func (c *Config) LookupApiKey(key uuid.UUID) (*ApiKey, bool) {
+	if key == uuid.Nil {
+		return nil, false
+	}
+	return c.findApiKey(func(apiKey *ApiKey) bool { return apiKey.Key == key })
+}
+
+// LookupApiKeyByName finds a key by its name, API_KEY is named default and the rotated one previous.
+func (c *Config) LookupApiKeyByName(name string) (*ApiKey, bool) {
+	return c.findApiKey(func(apiKey *ApiKey) bool { return apiKey.Name == name })
+}
+
+func (c *Config) findApiKey(match func(*ApiKey) bool) (*ApiKey, bool) {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+
+	if c.ApiKey != uuid.Nil {
+		if apiKey := c.masterApiKey(defaultKeyName, c.ApiKey); match(apiKey) {
+			return apiKey, true
+		}
+	}
+
+	if c.previousApiKey != uuid.Nil && time.Now().Before(c.previousApiKeyUntil) {
+		if apiKey := c.masterApiKey(previousKeyName, c.previousApiKey); match(apiKey) {
+			return apiKey, true
+		}
+	}
+
+	for _, apiKey := range c.ApiKeys {
+		if match(apiKey) {
+			return apiKey, true
+		}
+	}
+	return nil, false
+}
+
+// masterApiKey builds the entry of API_KEY or the previous one, they have every scope. keyMu must be held.
+func (c *Config) masterApiKey(name string, key uuid.UUID) *ApiKey {
+	return &ApiKey{
+		Name:             name,
+		Key:              key,
+		Scopes:           AllScopes,
+		CertFingerprints: c.apiKeyCertFingerprints,
+		CertSubjects:     c.apiKeyCertSubjects,
+	}
+}
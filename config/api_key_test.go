@@ -0,0 +1,137 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestApiKeys(t *testing.T) {
+	dashboard, deploy := uuid.New(), uuid.New()
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `[
+		{"name": "dashboard", "key": "` + dashboard.String() + `", "role": "read-only"},
+		{"name": "deploy", "key": "` + deploy.String() + `", "scopes": ["users", "stats"]}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadApiKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{ApiKey: uuid.New(), ApiKeys: keys}
+
+	key, ok := cfg.LookupApiKey(dashboard)
+	if !ok || !key.Allows(ScopeStats) || !key.Allows(ScopeLogs) || key.Allows(ScopeLifecycle) {
+		t.Errorf("unexpected dashboard key: %+v", key)
+	}
+
+	key, ok = cfg.LookupApiKey(deploy)
+	if !ok || !key.Allows(ScopeUsers) || key.Allows(ScopeLogs) {
+		t.Errorf("unexpected deploy key: %+v", key)
+	}
+
+	key, ok = cfg.LookupApiKey(cfg.ApiKey)
+	if !ok || !key.Allows(ScopeLifecycle) {
+		t.Errorf("API_KEY should have every scope: %+v", key)
+	}
+
+	if _, ok = cfg.LookupApiKey(uuid.New()); ok {
+		t.Error("unknown keys should be rejected")
+	}
+
+	if err = os.WriteFile(path, []byte(`[{"name": "bad", "key": "`+uuid.NewString()+`", "scopes": ["root"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadApiKeys(path); err == nil {
+		t.Error("unknown scopes should be rejected")
+	}
+}
+
+func TestApiKeyCertBinding(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "panel"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(der)
+
+	if !(&ApiKey{}).AllowsCert(nil) {
+		t.Error("unbound keys should be accepted without a certificate")
+	}
+	if (&ApiKey{CertSubjects: []string{"panel"}}).AllowsCert(nil) {
+		t.Error("bound keys should require a certificate")
+	}
+	if !(&ApiKey{CertSubjects: []string{"panel"}}).AllowsCert(cert) {
+		t.Error("the certificate subject should match")
+	}
+	if !(&ApiKey{CertFingerprints: []string{hex.EncodeToString(sum[:])}}).AllowsCert(cert) {
+		t.Error("the certificate fingerprint should match")
+	}
+	if (&ApiKey{CertSubjects: []string{"monitor"}}).AllowsCert(cert) {
+		t.Error("a different subject should not match")
+	}
+
+	// fingerprints are often copied in the colon separated upper case form
+	var colons []string
+	for _, b := range sum {
+		colons = append(colons, strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+
+	master := uuid.New()
+	cfg := &Config{ApiKey: master}
+	cfg.apiKeyCertFingerprints, cfg.apiKeyCertSubjects = parseCertBinding(strings.Join(colons, ":") + ", monitor")
+	if key, ok := cfg.LookupApiKey(master); !ok || !key.AllowsCert(cert) || key.AllowsCert(nil) {
+		t.Error("API_KEY should be bound to the API_KEY_CERT fingerprint")
+	}
+	if len(cfg.apiKeyCertSubjects) != 1 || cfg.apiKeyCertSubjects[0] != "monitor" {
+		t.Errorf("unexpected API_KEY_CERT subjects %v", cfg.apiKeyCertSubjects)
+	}
+}
+
+func TestReloadApiKeys(t *testing.T) {
+	old, rotated := uuid.New(), uuid.New()
+	cfg := &Config{ApiKey: old, ApiKeyGracePeriod: 60}
+
+	t.Setenv("API_KEY", rotated.String())
+	t.Setenv("API_KEYS_FILE", "")
+	if err := cfg.ReloadApiKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cfg.LookupApiKey(rotated); !ok {
+		t.Error("the new key should be accepted")
+	}
+	if _, ok := cfg.LookupApiKey(old); !ok {
+		t.Error("the old key should be accepted during the grace period")
+	}
+
+	cfg.previousApiKeyUntil = time.Now().Add(-time.Second)
+	if _, ok := cfg.LookupApiKey(old); ok {
+		t.Error("the old key should be rejected after the grace period")
+	}
+}
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -17,11 +23,45 @@ type Config struct {
 	XrayAssetsPath      string
 	SslCertFile         string
 	SslKeyFile          string
+	SslClientCaFile     string
 	ApiKey              uuid.UUID
-	ServiceProtocol     string
+	ApiKeys             []*ApiKey
+	ApiKeyGracePeriod   int
+	ServiceProtocol     string // grpc, rest or both on the same port
 	Debug               bool
 	GeneratedConfigPath string
 	LogBufferSize       int
+	IpLimitCooldown     int
+	Detached            bool
+	// RateLimit is the number of requests per minute allowed from one source ip, 0 disables it.
+	RateLimit       int
+	AuthMaxFailures int
+	AuthLockout     int
+	// PanelCidrs skip the rate limit and the auth lockout.
+	PanelCidrs []*net.IPNet
+	// Camouflage answers requests that fail auth with a decoy instead of an error, see CamouflageModes.
+	Camouflage       string
+	CamouflageTarget string
+	// SignedRequests requires every request to carry an HMAC signature instead of the bearer api key.
+	SignedRequests   bool
+	SignatureMaxSkew int
+	// AuditLogPath enables the audit log, AuditLogMaxSize is in megabytes.
+	AuditLogPath     string
+	AuditLogMaxSize  int
+	AuditLogMaxFiles int
+	// MetricsAddr enables the prometheus listener, e.g. 127.0.0.1:9550.
+	MetricsAddr string
+	// GrpcWeb serves gRPC-Web and Connect calls on the gRPC listener, browsers from GrpcWebOrigins are allowed by CORS.
+	GrpcWeb        bool
+	GrpcWebOrigins []string
+
+	// previousApiKey is still accepted until previousApiKeyUntil after API_KEY was rotated.
+	previousApiKey      uuid.UUID
+	previousApiKeyUntil time.Time
+	// apiKeyCert binds API_KEY and the previous key to client certificates, see API_KEY_CERT.
+	apiKeyCertFingerprints []string
+	apiKeyCertSubjects     []string
+	keyMu                  sync.RWMutex
 }
 
 func Load() (*Config, error) {
@@ -36,10 +76,32 @@ func Load() (*Config, error) {
 		XrayAssetsPath:      GetEnv("XRAY_ASSETS_PATH", "/usr/local/share/xray"),
 		SslCertFile:         GetEnv("SSL_CERT_FILE", "/var/lib/pg-Gate/certs/ssl_cert.pem"),
 		SslKeyFile:          GetEnv("SSL_KEY_FILE", "/var/lib/pg-Gate/certs/ssl_key.pem"),
+		SslClientCaFile:     GetEnv("SSL_CLIENT_CA_FILE", ""),
 		GeneratedConfigPath: GetEnv("GENERATED_CONFIG_PATH", "/var/lib/pg-Gate/generated/"),
-		ServiceProtocol:     GetEnv("SERVICE_PROTOCOL", "grpc"),
+		ServiceProtocol:     GetEnv("SERVICE_PROTOCOL", ProtocolGrpc),
 		Debug:               GetEnvAsBool("DEBUG", false),
 		LogBufferSize:       GetEnvAsInt("LOG_BUFFER_SIZE", 1000),
+		IpLimitCooldown:     GetEnvAsInt("IP_LIMIT_COOLDOWN", 300),
+		Detached:            GetEnvAsBool("DETACHED", false),
+		ApiKeyGracePeriod:   GetEnvAsInt("API_KEY_GRACE_PERIOD", 0),
+		RateLimit:           GetEnvAsInt("RATE_LIMIT", 0),
+		AuthMaxFailures:     GetEnvAsInt("AUTH_MAX_FAILURES", 5),
+		AuthLockout:         GetEnvAsInt("AUTH_LOCKOUT", 900),
+		Camouflage:          GetEnv("CAMOUFLAGE", ""),
+		CamouflageTarget:    GetEnv("CAMOUFLAGE_TARGET", ""),
+		SignedRequests:      GetEnvAsBool("SIGNED_REQUESTS", false),
+		SignatureMaxSkew:    GetEnvAsInt("SIGNATURE_MAX_SKEW", 300),
+		AuditLogPath:        GetEnv("AUDIT_LOG_PATH", ""),
+		AuditLogMaxSize:     GetEnvAsInt("AUDIT_LOG_MAX_SIZE", 10),
+		AuditLogMaxFiles:    GetEnvAsInt("AUDIT_LOG_MAX_FILES", 5),
+		MetricsAddr:         GetEnv("METRICS_ADDR", ""),
+		GrpcWeb:             GetEnvAsBool("GRPC_WEB", false),
+		GrpcWebOrigins:      splitList(GetEnv("GRPC_WEB_ORIGINS", "")),
+	}
+
+	cfg.PanelCidrs, err = ParseCidrs(GetEnv("PANEL_CIDRS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PANEL_CIDRS: %w", err)
 	}
 
 	cfg.ApiKey, err = GetEnvAsUUID("API_KEY")
@@ -47,6 +109,19 @@ func Load() (*Config, error) {
 		log.Printf("[Error] Failed to load API Key, error: %v", err)
 	}
 
+	cfg.apiKeyCertFingerprints, cfg.apiKeyCertSubjects = parseCertBinding(GetEnv("API_KEY_CERT", ""))
+
+	if apiKeysFile := GetEnv("API_KEYS_FILE", ""); apiKeysFile != "" {
+		cfg.ApiKeys, err = LoadApiKeys(apiKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load api keys file: %w", err)
+		}
+	}
+
+	if err = cfg.checkCamouflage(); err != nil {
+		return nil, err
+	}
+
 	GateHostStr := GetEnv("Gate_HOST", "0.0.0.0")
 	ipPattern := `^(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$`
 	re := regexp.MustCompile(ipPattern)
@@ -69,6 +144,84 @@ func NewTestConfig(generatedConfigPath string, key uuid.UUID) *Config {
 	return cfg
 }
 
+const (
+	ProtocolGrpc = "grpc"
+	ProtocolRest = "rest"
+	ProtocolBoth = "both"
+)
+
+const (
+	CamouflageNotFound = "404"
+	CamouflageStatic   = "static"
+	CamouflageProxy    = "proxy"
+)
+
+// CamouflageModes are the values of CAMOUFLAGE, static serves the CAMOUFLAGE_TARGET directory
+// and proxy forwards to the CAMOUFLAGE_TARGET url.
+var CamouflageModes = []string{CamouflageNotFound, CamouflageStatic, CamouflageProxy}
+
+func (c *Config) checkCamouflage() error {
+	switch c.Camouflage {
+	case "", CamouflageNotFound:
+		return nil
+	case CamouflageStatic:
+		if info, err := os.Stat(c.CamouflageTarget); err != nil || !info.IsDir() {
+			return fmt.Errorf("CAMOUFLAGE_TARGET %q is not a directory", c.CamouflageTarget)
+		}
+		return nil
+	case CamouflageProxy:
+		target, err := url.Parse(c.CamouflageTarget)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return fmt.Errorf("CAMOUFLAGE_TARGET %q is not a valid url", c.CamouflageTarget)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown CAMOUFLAGE mode %q, expected one of %v", c.Camouflage, CamouflageModes)
+	}
+}
+
+// splitList splits a comma separated list and drops empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseCidrs parses a comma separated list of networks, plain ips are taken as a single address.
+func ParseCidrs(value string) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
 func GetEnv(key, fallback string) string {
 	value, exists := os.LookupEnv(key)
 	if !exists {
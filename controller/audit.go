@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+)
+
+// AuditedMethods change the node and are written to the audit log, REST routes use the same names.
+var AuditedMethods = map[string]bool{
+	"Start":       true,
+	"Stop":        true,
+	"SyncUser":    true,
+	"SyncUsers":   true,
+	"RemoveUsers": true,
+	"SetPolicies": true,
+	"ClearBans":   true,
+}
+
+type auditCtxKey struct{}
+
+// BeginAudit starts the audit entry of a request, handlers fill in what changed through Audit.
+func (c *Controller) BeginAudit(ctx context.Context, method, ip, sessionID string) (context.Context, *common.AuditEntry) {
+	entry := &common.AuditEntry{
+		Time:      time.Now().Unix(),
+		Ip:        ip,
+		SessionId: sessionID,
+		Method:    method,
+		Changes:   &common.AuditChanges{},
+	}
+	if key, ok := ApiKeyFrom(ctx); ok {
+		entry.Key = key.Name
+	}
+	return context.WithValue(ctx, auditCtxKey{}, entry), entry
+}
+
+// EndAudit writes the entry with the result code of the request.
+func (c *Controller) EndAudit(entry *common.AuditEntry, code string) {
+	entry.Code = code
+	if err := c.audit.Write(entry); err != nil {
+		log.Println("failed to write audit log:", err)
+	}
+}
+
+// Audit returns the audit entry of the request, requests that aren't audited get a throwaway one.
+func Audit(ctx context.Context) *common.AuditEntry {
+	if entry, ok := ctx.Value(auditCtxKey{}).(*common.AuditEntry); ok {
+		return entry
+	}
+	return &common.AuditEntry{Changes: &common.AuditChanges{}}
+}
+
+// AuditSync records the result of a full user sync.
+func AuditSync(ctx context.Context, response *common.SyncUsersResponse) {
+	changes := Audit(ctx).Changes
+	changes.UsersAdded = response.GetAdded()
+	changes.UsersRemoved = response.GetRemoved()
+	changes.UsersUpdated = response.GetUpdated()
+	changes.CoreRestarted = response.GetRestarted()
+}
+
+// AuditRemove records the users a removal dropped.
+func AuditRemove(ctx context.Context, response *common.RemoveUsersResponse) {
+	changes := Audit(ctx).Changes
+	changes.Users = response.GetRemoved()
+	changes.UsersRemoved = uint32(len(response.GetRemoved()))
+	changes.CoreRestarted = response.GetRestarted()
+}
+
+// auditRestart writes an entry for a restart the backend did on its own,
+// the method names the cause, e.g. Enforcement or HealthCheck.
+func (c *Controller) auditRestart(reason string, users []string) {
+	c.EndAudit(&common.AuditEntry{
+		Time:    time.Now().Unix(),
+		Method:  reason,
+		Changes: &common.AuditChanges{Users: users, CoreRestarted: true},
+	}, "OK")
+}
+
+func (c *Controller) QueryAuditLog(request *common.AuditLogRequest) (*common.AuditLogResponse, error) {
+	entries, err := c.audit.Query(request)
+	if err != nil {
+		return nil, err
+	}
+	return &common.AuditLogResponse{Entries: entries}, nil
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+)
+
+func TestAuditRestart(t *testing.T) {
+	c := New(&config.Config{AuditLogPath: filepath.Join(t.TempDir(), "audit.log"), AuditLogMaxSize: 1, AuditLogMaxFiles: 1})
+
+	c.auditRestart("Enforcement", []string{"user1"})
+	c.auditRestart("HealthCheck", nil)
+
+	response, err := c.QueryAuditLog(&common.AuditLogRequest{Method: "Enforcement"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.GetEntries()) != 1 {
+		t.Fatalf("expected a single enforcement entry, got %d", len(response.GetEntries()))
+	}
+
+	entry := response.GetEntries()[0]
+	if !entry.GetChanges().GetCoreRestarted() || len(entry.GetChanges().GetUsers()) != 1 || entry.GetCode() != "OK" {
+		t.Errorf("unexpected enforcement entry: %v", entry)
+	}
+}
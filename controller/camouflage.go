@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/Rexa/Gate/config"
+)
+
+// NewDecoy returns the handler that answers requests failing auth in camouflage mode,
+// it is nil when camouflage is disabled.
+func NewDecoy(cfg *config.Config) http.Handler {
+	switch cfg.Camouflage {
+	case config.CamouflageStatic:
+		return http.FileServer(http.Dir(cfg.CamouflageTarget))
+	case config.CamouflageProxy:
+		// the target was validated when the config was loaded
+		if target, err := url.Parse(cfg.CamouflageTarget); err == nil {
+			return httputil.NewSingleHostReverseProxy(target)
+		}
+		return http.NotFoundHandler()
+	case config.CamouflageNotFound:
+		return http.NotFoundHandler()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rexa/Gate/config"
+)
+
+func TestDecoy(t *testing.T) {
+	if NewDecoy(&config.Config{}) != nil {
+		t.Fatal("camouflage should be disabled by default")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>welcome</h1>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cfg    *config.Config
+		status int
+		body   string
+	}{
+		{&config.Config{Camouflage: config.CamouflageNotFound}, http.StatusNotFound, "404 page not found\n"},
+		{&config.Config{Camouflage: config.CamouflageStatic, CamouflageTarget: dir}, http.StatusOK, "<h1>welcome</h1>"},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		NewDecoy(tt.cfg).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if recorder.Code != tt.status || recorder.Body.String() != tt.body {
+			t.Errorf("%s decoy answered %d %q", tt.cfg.Camouflage, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/http"
 	"sync"
-	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/Rexa/Gate/audit"
 	"github.com/Rexa/Gate/backend"
 	"github.com/Rexa/Gate/backend/xray"
 	"github.com/Rexa/Gate/common"
@@ -20,52 +21,56 @@ const GateVersion = "0.1.4"
 
 type Service interface {
 	Disconnect()
+	MetricsHandler() http.Handler
+	ProbeHandler() http.Handler
 }
 
 type Controller struct {
-	backend     backend.Backend
-	cfg         *config.Config
-	apiPort     int
-	clientIP    string
-	lastRequest time.Time
-	stats       *common.SystemStatsResponse
-	cancelFunc  context.CancelFunc
+	backend  backend.Backend
+	cfg      *config.Config
+	apiPort  int
+	stats    *common.SystemStatsResponse
+	sessions map[string]*session
+	// primary is the id of the session that controls the core.
+	primary     string
+	statsCancel context.CancelFunc
+	configHash  string
+	guard       *guard
+	nonces      *nonceCache
+	audit       *audit.Log
 	mu          sync.RWMutex
 }
 
+// ConfigHash identifies the non-user parts of the backend config carried by ctx.
+func ConfigHash(ctx context.Context) (string, error) {
+	switch config := ctx.Value(backend.ConfigKey{}).(type) {
+	case *xray.Config:
+		return config.Hash()
+	default:
+		return "", errors.New("backend config has not been initialized")
+	}
+}
+
 func New(cfg *config.Config) *Controller {
-	_, cancel := context.WithCancel(context.Background())
 	return &Controller{
-		cfg:        cfg,
-		apiPort:    tools.FindFreePort(),
-		cancelFunc: cancel,
+		cfg:      cfg,
+		apiPort:  tools.FindFreePort(),
+		sessions: make(map[string]*session),
+		guard:    newGuard(cfg),
+		nonces:   newNonceCache(),
+		audit:    audit.New(cfg.AuditLogPath, int64(cfg.AuditLogMaxSize)<<20, cfg.AuditLogMaxFiles),
 	}
 }
 
-func (c *Controller) ApiKey() uuid.UUID {
+func (c *Controller) LookupApiKey(key uuid.UUID) (*config.ApiKey, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.cfg.ApiKey
-}
-
-func (c *Controller) Connect(ip string, keepAlive uint64) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.lastRequest = time.Now()
-	c.clientIP = ip
-
-	ctx, cancel := context.WithCancel(context.Background())
-	c.cancelFunc = cancel
-	go c.recordSystemStats(ctx)
-	if keepAlive > 0 {
-		go c.keepAliveTracker(ctx, time.Duration(keepAlive)*time.Second)
-	}
+	return c.cfg.LookupApiKey(key)
 }
 
 func (c *Controller) Disconnect() {
-	c.cancelFunc()
-
 	c.mu.Lock()
+	c.endSession(c.primary)
 	backend := c.backend
 	c.mu.Unlock()
 
@@ -80,32 +85,49 @@ func (c *Controller) Disconnect() {
 
 	c.backend = nil
 	c.apiPort = tools.FindFreePort()
-	c.clientIP = ""
-}
-
-func (c *Controller) Ip() string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.clientIP
+	c.configHash = ""
 }
 
-func (c *Controller) NewRequest() {
+// Release ends the primary session but leaves the backend serving the last known users,
+// so the next panel can take it over.
+func (c *Controller) Release() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.lastRequest = time.Now()
+
+	c.endSession(c.primary)
+}
+
+// TakeOver hands the running backend over to a new panel when it runs the same config.
+// The users of the new panel are applied as a live sync instead of restarting the core.
+func (c *Controller) TakeOver(ctx context.Context, configHash string, users []*common.User) (bool, error) {
+	c.mu.RLock()
+	back := c.backend
+	ok := back != nil && back.Started() && c.configHash == configHash
+	c.mu.RUnlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	if _, err := back.SyncUsers(ctx, users); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (c *Controller) StartBackend(ctx context.Context, backendType common.BackendType) error {
+func (c *Controller) StartBackend(ctx context.Context, backendType common.BackendType, configHash string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	switch backendType {
 	case common.BackendType_XRAY:
+		ctx = context.WithValue(ctx, backend.RestartHookKey{}, backend.RestartHook(c.auditRestart))
 		newBackend, err := xray.NewXray(ctx, c.apiPort, c.cfg)
 		if err != nil {
 			return err
 		}
 		c.backend = newBackend
+		c.configHash = configHash
 	default:
 		return errors.New("invalid backend type")
 	}
@@ -119,26 +141,6 @@ func (c *Controller) Backend() backend.Backend {
 	return c.backend
 }
 
-func (c *Controller) keepAliveTracker(ctx context.Context, keepAlive time.Duration) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			c.mu.RLock()
-			lastRequest := c.lastRequest
-			c.mu.RUnlock()
-			if time.Since(lastRequest) >= keepAlive {
-				log.Println("disconnect automatically due to keep alive timeout")
-				c.Disconnect()
-			}
-		}
-	}
-}
-
 func (c *Controller) recordSystemStats(ctx context.Context) {
 	for {
 		select {
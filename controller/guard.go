@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"cmp"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+)
+
+const (
+	rateLimitWindow = time.Minute
+	// guardPruneInterval is how often idle clients are dropped from memory.
+	guardPruneInterval = 5 * time.Minute
+	// guardMaxClients bounds the memory of the guard, the least recently seen clients are evicted beyond it.
+	guardMaxClients = 1 << 16
+	// ipv6ClientBits groups ipv6 sources by their /64, a single host usually owns the whole prefix.
+	ipv6ClientBits = 64
+)
+
+type client struct {
+	lastSeen    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	lastFailure time.Time
+	bannedUntil time.Time
+}
+
+// guard rate limits requests and locks out source ips after repeated auth failures.
+type guard struct {
+	cfg       *config.Config
+	clients   map[string]*client
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+func newGuard(cfg *config.Config) *guard {
+	return &guard{cfg: cfg, clients: make(map[string]*client), lastPrune: time.Now()}
+}
+
+// SourceIp strips the port from a remote address.
+func SourceIp(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// clientKey is the key the ip is tracked under, ipv6 addresses are grouped by prefix
+// so rotating addresses doesn't escape the limits.
+func clientKey(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() != nil {
+		return ip
+	}
+	mask := net.CIDRMask(ipv6ClientBits, 8*net.IPv6len)
+	return (&net.IPNet{IP: parsed.Mask(mask), Mask: mask}).String()
+}
+
+func (g *guard) allowlisted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, cidr := range g.cfg.PanelCidrs {
+		if cidr.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *guard) lockout() time.Duration {
+	return time.Duration(g.cfg.AuthLockout) * time.Second
+}
+
+// client must be called with mu held.
+func (g *guard) client(ip string, now time.Time) *client {
+	if now.Sub(g.lastPrune) >= guardPruneInterval {
+		g.prune(now)
+	}
+
+	key := clientKey(ip)
+	c, ok := g.clients[key]
+	if !ok {
+		if len(g.clients) >= guardMaxClients {
+			g.evict(now)
+		}
+		c = &client{windowStart: now}
+		g.clients[key] = c
+	}
+	c.lastSeen = now
+	return c
+}
+
+// evict prunes idle clients and, if that is not enough, drops the least recently seen tenth of them.
+// It must be called with mu held.
+func (g *guard) evict(now time.Time) {
+	g.prune(now)
+	if len(g.clients) < guardMaxClients {
+		return
+	}
+
+	keys := make([]string, 0, len(g.clients))
+	for key := range g.clients {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return g.clients[a].lastSeen.Compare(g.clients[b].lastSeen)
+	})
+	for _, key := range keys[:len(keys)/10] {
+		delete(g.clients, key)
+	}
+}
+
+// prune must be called with mu held.
+func (g *guard) prune(now time.Time) {
+	g.lastPrune = now
+	for ip, c := range g.clients {
+		if now.Sub(c.windowStart) >= rateLimitWindow && now.After(c.bannedUntil) && now.Sub(c.lastFailure) >= g.lockout() {
+			delete(g.clients, ip)
+		}
+	}
+}
+
+// Admit counts a request from ip, it returns how long the client has to wait when it is banned or over the rate limit.
+func (c *Controller) Admit(ip string) (time.Duration, bool) {
+	g := c.guard
+	if g.allowlisted(ip) {
+		return 0, true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	cl := g.client(ip, now)
+	if now.Before(cl.bannedUntil) {
+		return cl.bannedUntil.Sub(now), false
+	}
+
+	if g.cfg.RateLimit <= 0 {
+		return 0, true
+	}
+	if now.Sub(cl.windowStart) >= rateLimitWindow {
+		cl.windowStart, cl.requests = now, 0
+	}
+	cl.requests++
+	if cl.requests > g.cfg.RateLimit {
+		return cl.windowStart.Add(rateLimitWindow).Sub(now), false
+	}
+	return 0, true
+}
+
+// AuthFailed records a failed authentication, the ip is locked out once it reaches AuthMaxFailures.
+// Failures older than the lockout period are forgotten.
+func (c *Controller) AuthFailed(ip string) {
+	g := c.guard
+	if g.cfg.AuthMaxFailures <= 0 || g.allowlisted(ip) {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	cl := g.client(ip, now)
+	if now.Sub(cl.lastFailure) >= g.lockout() {
+		cl.failures = 0
+	}
+	cl.failures++
+	cl.lastFailure = now
+	if cl.failures >= g.cfg.AuthMaxFailures {
+		cl.bannedUntil = now.Add(g.lockout())
+	}
+}
+
+// AuthSucceeded forgets earlier failures of the ip.
+func (c *Controller) AuthSucceeded(ip string) {
+	g := c.guard
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if cl, ok := g.clients[clientKey(ip)]; ok {
+		cl.failures = 0
+	}
+}
+
+// Bans lists the ips with recent auth failures, banned ones have BannedUntil set.
+// Ipv6 sources are listed by their /64 prefix.
+func (c *Controller) Bans() *common.BansResponse {
+	g := c.guard
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	response := &common.BansResponse{}
+	for ip, cl := range g.clients {
+		banned := now.Before(cl.bannedUntil)
+		if !banned && (cl.failures == 0 || now.Sub(cl.lastFailure) >= g.lockout()) {
+			continue
+		}
+
+		ban := &common.Ban{Ip: ip, Failures: uint32(cl.failures)}
+		if banned {
+			ban.BannedUntil = cl.bannedUntil.Unix()
+		}
+		response.Bans = append(response.Bans, ban)
+	}
+
+	slices.SortFunc(response.Bans, func(a, b *common.Ban) int {
+		return cmp.Compare(a.GetIp(), b.GetIp())
+	})
+	return response
+}
+
+// ClearBans lifts the bans and failures of the given ips, or of every ip when none are given.
+func (c *Controller) ClearBans(ips []string) uint32 {
+	g := c.guard
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(ips) == 0 {
+		for ip := range g.clients {
+			ips = append(ips, ip)
+		}
+	}
+
+	now := time.Now()
+	var cleared uint32
+	for _, ip := range ips {
+		cl, ok := g.clients[clientKey(ip)]
+		if !ok {
+			continue
+		}
+		if now.Before(cl.bannedUntil) || cl.failures > 0 {
+			cleared++
+		}
+		cl.failures = 0
+		cl.bannedUntil = time.Time{}
+	}
+	return cleared
+}
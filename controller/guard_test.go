@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Rexa/Gate/config"
+)
+
+func TestGuard(t *testing.T) {
+	panel, err := config.ParseCidrs("10.0.0.0/24, 192.168.1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(&config.Config{RateLimit: 3, AuthMaxFailures: 2, AuthLockout: 60, PanelCidrs: panel})
+
+	for i := 0; i < 3; i++ {
+		if _, ok := c.Admit("203.0.113.1"); !ok {
+			t.Fatalf("request %d should be within the rate limit", i+1)
+		}
+	}
+	if wait, ok := c.Admit("203.0.113.1"); ok || wait <= 0 {
+		t.Fatal("the fourth request should be rate limited")
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := c.Admit("10.0.0.7"); !ok {
+			t.Fatal("panel cidrs should skip the rate limit")
+		}
+	}
+
+	c.AuthFailed("203.0.113.2")
+	c.AuthSucceeded("203.0.113.2")
+	c.AuthFailed("203.0.113.2")
+	if _, ok := c.Admit("203.0.113.2"); !ok {
+		t.Fatal("a successful auth should reset the failures")
+	}
+	c.AuthFailed("203.0.113.2")
+	if _, ok := c.Admit("203.0.113.2"); ok {
+		t.Fatal("the ip should be locked out after repeated failures")
+	}
+
+	c.AuthFailed("192.168.1.5")
+	c.AuthFailed("192.168.1.5")
+	if _, ok := c.Admit("192.168.1.5"); !ok {
+		t.Fatal("panel ips should not be locked out")
+	}
+
+	bans := c.Bans().GetBans()
+	if len(bans) != 1 || bans[0].GetIp() != "203.0.113.2" || bans[0].GetBannedUntil() == 0 {
+		t.Fatalf("unexpected bans: %v", bans)
+	}
+	if cleared := c.ClearBans(nil); cleared != 1 {
+		t.Fatalf("expected 1 cleared ban, got %d", cleared)
+	}
+	if _, ok := c.Admit("203.0.113.2"); !ok {
+		t.Fatal("a cleared ip should be admitted again")
+	}
+}
+
+func TestGuardIpv6Prefix(t *testing.T) {
+	c := New(&config.Config{RateLimit: 100, AuthMaxFailures: 2, AuthLockout: 60})
+
+	c.AuthFailed("2001:db8:0:1::1")
+	c.AuthFailed("2001:db8:0:1::2")
+	if _, ok := c.Admit("2001:db8:0:1::3"); ok {
+		t.Fatal("addresses in the same /64 should share a lockout")
+	}
+	if _, ok := c.Admit("2001:db8:0:2::1"); !ok {
+		t.Fatal("another /64 should not be locked out")
+	}
+
+	bans := c.Bans().GetBans()
+	if len(bans) != 1 || bans[0].GetIp() != "2001:db8:0:1::/64" {
+		t.Fatalf("unexpected bans: %v", bans)
+	}
+	if cleared := c.ClearBans([]string{"2001:db8:0:1::1"}); cleared != 1 {
+		t.Fatalf("expected the /64 to be cleared by one of its addresses, got %d", cleared)
+	}
+}
+
+func TestGuardMaxClients(t *testing.T) {
+	c := New(&config.Config{RateLimit: 100})
+
+	for i := 0; i < guardMaxClients+10; i++ {
+		c.Admit(fmt.Sprintf("10.%d.%d.%d", i>>16, (i>>8)&0xff, i&0xff))
+	}
+	if len(c.guard.clients) > guardMaxClients {
+		t.Fatalf("the guard should be bounded, it tracks %d clients", len(c.guard.clients))
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Rexa/Gate/common"
+)
+
+// Ready reports whether the node can serve traffic: a backend is started and passed its last health check.
+// The error is safe to show to unauthenticated probes.
+func (c *Controller) Ready() error {
+	back := c.Backend()
+	switch {
+	case back == nil || !back.Started():
+		return errors.New("core is not started")
+	case !back.Healthy():
+		return errors.New("core failed its last health check")
+	default:
+		return nil
+	}
+}
+
+// ProbeHandler answers /healthz and /readyz next to the metrics, camouflaged nodes
+// don't answer probes on the api address.
+func (c *Controller) ProbeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", c.Healthz)
+	mux.HandleFunc("GET /readyz", c.Readyz)
+	return mux
+}
+
+func (c *Controller) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (c *Controller) Readyz(w http.ResponseWriter, _ *http.Request) {
+	if err := c.Ready(); err != nil {
+		common.SendError(w, codes.Unavailable, "not ready: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ready\n"))
+}
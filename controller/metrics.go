@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/metrics"
+	"github.com/Rexa/Gate/tools"
+)
+
+// systemStatsInterval is how often the system stats are sampled for scrapes while no session records them.
+const systemStatsInterval = 15 * time.Second
+
+// MetricsHandler serves the Gate metrics along with the system, core and traffic stats.
+func (c *Controller) MetricsHandler() http.Handler {
+	go c.sampleSystemStats()
+	return metrics.Handler(metricsCollector{c})
+}
+
+// sampleSystemStats keeps the system stats fresh for scrapes, sampling them takes about a second
+// so it is never done while serving one.
+func (c *Controller) sampleSystemStats() {
+	ticker := time.NewTicker(systemStatsInterval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		c.mu.RLock()
+		recording := c.statsCancel != nil
+		c.mu.RUnlock()
+		if recording {
+			continue
+		}
+
+		stats, err := tools.GetSystemStats()
+		if err != nil {
+			log.Println("metrics: failed to get system stats:", err)
+			continue
+		}
+		c.mu.Lock()
+		c.stats = stats
+		c.mu.Unlock()
+	}
+}
+
+// metricsCollector reads the stats at scrape time. It is unchecked,
+// the traffic series depend on the inbounds and users of the running core.
+type metricsCollector struct {
+	c *Controller
+}
+
+func (metricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (m metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m.c.collectSystemStats(ch)
+
+	back := m.c.Backend()
+	started := back != nil && back.Started()
+
+	ch <- constMetric("gate_core_started", "Whether the core is running.", prometheus.GaugeValue, boolValue(started))
+	if !started {
+		return
+	}
+
+	if stats, err := back.GetSysStats(ctx); err != nil {
+		log.Println("metrics: failed to get backend stats:", err)
+	} else {
+		collectBackendStats(ch, stats)
+	}
+
+	// the traffic is exported as gauges, the panel resets the core stats on every poll so they aren't monotonic
+	traffic := []struct {
+		statType common.StatType
+		name     string
+		label    string
+		help     string
+	}{
+		{common.StatType_Inbounds, "gate_xray_inbound_traffic_bytes", "tag", "Traffic of each inbound since the stats were last reset, panels reset them when they poll."},
+		{common.StatType_Outbounds, "gate_xray_outbound_traffic_bytes", "tag", "Traffic of each outbound since the stats were last reset, panels reset them when they poll."},
+		{common.StatType_UsersStat, "gate_xray_user_traffic_bytes", "user", "Traffic of each user since the stats were last reset, panels reset them when they poll."},
+	}
+	for _, t := range traffic {
+		stats, err := back.GetStats(ctx, &common.StatRequest{Type: t.statType})
+		if err != nil {
+			log.Printf("metrics: failed to get %s stats: %v", t.statType, err)
+			continue
+		}
+
+		desc := prometheus.NewDesc(t.name, t.help, []string{t.label, "direction"}, nil)
+		for _, stat := range stats.GetStats() {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(stat.GetValue()), stat.GetName(), direction(stat))
+		}
+	}
+}
+
+// collectSystemStats uses the stats recorded for the panel or sampled by sampleSystemStats.
+func (c *Controller) collectSystemStats(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	stats := c.stats
+	c.mu.RUnlock()
+
+	if stats == nil {
+		return
+	}
+
+	gauges := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"gate_system_memory_total_bytes", "Total memory of the host.", float64(stats.GetMemTotal())},
+		{"gate_system_memory_used_bytes", "Used memory of the host.", float64(stats.GetMemUsed())},
+		{"gate_system_cpu_cores", "Logical cpu cores of the host.", float64(stats.GetCpuCores())},
+		{"gate_system_cpu_usage_percent", "Cpu usage of the host.", stats.GetCpuUsage()},
+		{"gate_system_incoming_bandwidth_bytes_per_second", "Incoming bandwidth of the host.", float64(stats.GetIncomingBandwidthSpeed())},
+		{"gate_system_outgoing_bandwidth_bytes_per_second", "Outgoing bandwidth of the host.", float64(stats.GetOutgoingBandwidthSpeed())},
+	}
+	for _, g := range gauges {
+		ch <- constMetric(g.name, g.help, prometheus.GaugeValue, g.value)
+	}
+}
+
+func collectBackendStats(ch chan<- prometheus.Metric, stats *common.BackendStatsResponse) {
+	samples := []struct {
+		name  string
+		kind  prometheus.ValueType
+		help  string
+		value float64
+	}{
+		{"gate_xray_goroutines", prometheus.GaugeValue, "Goroutines of the core.", float64(stats.GetNumGoroutine())},
+		{"gate_xray_gc_total", prometheus.CounterValue, "Garbage collections of the core.", float64(stats.GetNumGc())},
+		{"gate_xray_alloc_bytes", prometheus.GaugeValue, "Heap memory allocated by the core.", float64(stats.GetAlloc())},
+		{"gate_xray_alloc_bytes_total", prometheus.CounterValue, "Heap memory allocated by the core over its lifetime.", float64(stats.GetTotalAlloc())},
+		{"gate_xray_sys_bytes", prometheus.GaugeValue, "Memory the core obtained from the system.", float64(stats.GetSys())},
+		{"gate_xray_mallocs_total", prometheus.CounterValue, "Heap objects allocated by the core.", float64(stats.GetMallocs())},
+		{"gate_xray_frees_total", prometheus.CounterValue, "Heap objects freed by the core.", float64(stats.GetFrees())},
+		{"gate_xray_live_objects", prometheus.GaugeValue, "Live heap objects of the core.", float64(stats.GetLiveObjects())},
+		{"gate_xray_gc_pause_seconds_total", prometheus.CounterValue, "Time the core spent in garbage collection pauses.", float64(stats.GetPauseTotalNs()) / 1e9},
+		{"gate_xray_uptime_seconds", prometheus.GaugeValue, "Uptime of the core.", float64(stats.GetUptime())},
+	}
+	for _, s := range samples {
+		ch <- constMetric(s.name, s.help, s.kind, s.value)
+	}
+}
+
+func constMetric(name, help string, kind prometheus.ValueType, value float64) prometheus.Metric {
+	return prometheus.MustNewConstMetric(prometheus.NewDesc(name, help, nil, nil), kind, value)
+}
+
+// direction finds uplink or downlink, user stats and inbound/outbound stats put it in different fields.
+func direction(stat *common.Stat) string {
+	if stat.GetLink() == "uplink" || stat.GetLink() == "downlink" {
+		return stat.GetLink()
+	}
+	return stat.GetType()
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
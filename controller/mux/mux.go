@@ -0,0 +1,74 @@
+package mux
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+	"github.com/Rexa/Gate/controller/rest"
+	"github.com/Rexa/Gate/controller/rpc"
+)
+
+// Handler sends gRPC, gRPC-Web and Connect calls to grpcHandler and everything else to restHandler.
+func Handler(restHandler, grpcHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPC(r) {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+
+		restHandler.ServeHTTP(w, r)
+	})
+}
+
+// isGRPC checks the content type, gRPC always negotiates h2 through ALPN while REST requests can come
+// over HTTP/1.1 or h2. Connect unary calls use the plain json and protobuf content types, their path
+// tells them apart from REST.
+func isGRPC(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/grpc-web"), strings.HasPrefix(contentType, "application/connect+"):
+		return true
+	case strings.HasPrefix(contentType, "application/grpc"):
+		return r.ProtoMajor == 2
+	}
+	return strings.HasPrefix(r.URL.Path, "/"+common.GateService_ServiceDesc.ServiceName+"/")
+}
+
+// StartListener serves the REST and gRPC services on one TLS listener, both on top of the same controller.
+func StartListener(tlsConfig *tls.Config, addr string, cfg *config.Config) (func(ctx context.Context) error, controller.Service, error) {
+	c := controller.New(cfg)
+	restService := rest.New(cfg, c)
+	rpcService := rpc.New(c)
+
+	grpcHandler := rpc.HTTPHandler(rpcService, rpc.NewGRPCServer(rpcService), cfg)
+
+	// ServeTLS adds h2 and http/1.1 to the protocols offered through ALPN
+	httpServer := &http.Server{
+		TLSConfig: tlsConfig,
+		Handler:   Handler(restService.Router, grpcHandler),
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	go func() {
+		log.Println("HTTP and gRPC Server listening on", addr)
+		log.Println("Press Ctrl+C to stop")
+		if err := httpServer.ServeTLS(listener, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("server error: %v", err)
+		}
+	}()
+
+	return httpServer.Shutdown, restService, nil
+}
@@ -0,0 +1,46 @@
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	named := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(name))
+		})
+	}
+	handler := Handler(named("rest"), named("grpc"))
+
+	tests := []struct {
+		protoMajor  int
+		path        string
+		contentType string
+		expected    string
+	}{
+		{2, "/service.GateService/GetBaseInfo", "application/grpc", "grpc"},
+		{2, "/service.GateService/GetBaseInfo", "application/grpc+proto", "grpc"},
+		{2, "/v2/stats/users", "application/x-protobuf", "rest"},
+		{2, "/v2/stats/users", "application/json", "rest"},
+		{1, "/service.GateService/GetBaseInfo", "application/grpc", "rest"},
+		{1, "/service.GateService/GetBaseInfo", "application/grpc-web+proto", "grpc"},
+		{1, "/service.GateService/GetBaseInfo", "application/grpc-web-text", "grpc"},
+		{1, "/service.GateService/GetLogs", "application/connect+json", "grpc"},
+		{1, "/service.GateService/GetBaseInfo", "application/json", "grpc"},
+		{2, "/service.GateService/GetBaseInfo", "application/proto", "grpc"},
+	}
+
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		request.ProtoMajor = tt.protoMajor
+		request.Header.Set("Content-Type", tt.contentType)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Body.String() != tt.expected {
+			t.Errorf("HTTP/%d %s %s went to %s", tt.protoMajor, tt.path, tt.contentType, recorder.Body.String())
+		}
+	}
+}
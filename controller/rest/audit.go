@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func (s *Service) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	var request common.AuditLogRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	s.getAuditLog(w, r, &request)
+}
+
+func (s *Service) getAuditLog(w http.ResponseWriter, r *http.Request, request *common.AuditLogRequest) {
+	response, err := s.QueryAuditLog(request)
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+
+	common.SendProtoResponse(w, r, response)
+}
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func (s *Service) GetBans(w http.ResponseWriter, r *http.Request) {
+	common.SendProtoResponse(w, r, s.Bans())
+}
+
+func (s *Service) ClearBans(w http.ResponseWriter, r *http.Request) {
+	var request common.ClearBansRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	s.clearBans(w, r, &request)
+}
+
+func (s *Service) clearBans(w http.ResponseWriter, r *http.Request, request *common.ClearBansRequest) {
+	common.SendProtoResponse(w, r, &common.ClearBansResponse{Cleared: s.Controller.ClearBans(request.GetIps())})
+}
@@ -3,29 +3,68 @@ package rest
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
 
+	"google.golang.org/grpc/codes"
+
 	"github.com/Rexa/Gate/backend"
 	"github.com/Rexa/Gate/backend/xray"
 	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
 )
 
-func (s *Service) Base(w http.ResponseWriter, _ *http.Request) {
-	common.SendProtoResponse(w, s.BaseInfoResponse())
+func (s *Service) Base(w http.ResponseWriter, r *http.Request) {
+	common.SendProtoResponse(w, r, s.BaseInfoResponse())
 }
 
 func (s *Service) Start(w http.ResponseWriter, r *http.Request) {
-	ctx, backendType, keepAlive, err := s.detectBackend(r)
+	ctx, detail, err := s.detectBackend(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		common.SendError(w, codes.Unavailable, err.Error())
 		return
 	}
 
 	ip, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		http.Error(w, "unknown ip", http.StatusServiceUnavailable)
+		common.SendError(w, codes.Unavailable, "unknown ip")
+		return
+	}
+
+	if !detail.GetSecondary() && !controller.Allows(r.Context(), config.ScopeLifecycle) {
+		common.SendError(w, codes.PermissionDenied, fmt.Sprintf("api key is missing the %s scope", config.ScopeLifecycle))
+		return
+	}
+
+	if detail.GetSecondary() {
+		response := s.BaseInfoResponse()
+		response.SessionId = s.Attach(ip, detail.GetKeepAlive())
+		controller.Audit(ctx).SessionId = response.SessionId
+		common.SendProtoResponse(w, r, response)
+		return
+	}
+
+	configHash, err := controller.ConfigHash(ctx)
+	if err != nil {
+		common.SendError(w, codes.Unavailable, err.Error())
+		return
+	}
+	controller.Audit(ctx).Changes.ConfigHash = configHash
+
+	takenOver, err := s.TakeOver(ctx, configHash, detail.GetUsers())
+	if err != nil {
+		common.SendError(w, codes.Unavailable, err.Error())
+		return
+	}
+	if takenOver {
+		log.Println("New connection from ", ip, " took over the running core, config is unchanged.")
+		response := s.BaseInfoResponse()
+		response.SessionId = s.Connect(ip, detail.GetKeepAlive(), detail.GetDetached())
+		controller.Audit(ctx).SessionId = response.SessionId
+		common.SendProtoResponse(w, r, response)
 		return
 	}
 
@@ -34,41 +73,66 @@ func (s *Service) Start(w http.ResponseWriter, r *http.Request) {
 		s.Disconnect()
 	}
 
-	s.Connect(ip, keepAlive)
+	sessionID := s.Connect(ip, detail.GetKeepAlive(), detail.GetDetached())
 
-	if err = s.StartBackend(ctx, backendType); err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	if err = s.StartBackend(ctx, detail.GetType(), configHash); err != nil {
+		common.SendError(w, codes.Unavailable, err.Error())
 		return
 	}
+	controller.Audit(ctx).Changes.CoreRestarted = true
+	controller.Audit(ctx).SessionId = sessionID
 
-	common.SendProtoResponse(w, s.BaseInfoResponse())
+	response := s.BaseInfoResponse()
+	response.SessionId = sessionID
+	common.SendProtoResponse(w, r, response)
 }
 
-func (s *Service) Stop(w http.ResponseWriter, _ *http.Request) {
+func (s *Service) Stop(w http.ResponseWriter, r *http.Request) {
+	if id := r.Header.Get(controller.SessionHeader); !s.IsPrimary(id, controller.SourceIp(r.RemoteAddr)) {
+		if id == "" {
+			common.SendError(w, codes.PermissionDenied, "session does not control the core")
+			return
+		}
+		s.EndSession(id)
+		common.SendProtoResponse(w, r, &common.Empty{})
+		return
+	}
+
+	if !controller.Allows(r.Context(), config.ScopeLifecycle) {
+		common.SendError(w, codes.PermissionDenied, fmt.Sprintf("api key is missing the %s scope", config.ScopeLifecycle))
+		return
+	}
+
 	s.Disconnect()
+	controller.Audit(r.Context()).Changes.CoreStopped = true
 
-	common.SendProtoResponse(w, &common.Empty{})
+	common.SendProtoResponse(w, r, &common.Empty{})
 }
 
-func (s *Service) detectBackend(r *http.Request) (context.Context, common.BackendType, uint64, error) {
+func (s *Service) detectBackend(r *http.Request) (context.Context, *common.Backend, error) {
 	var data common.Backend
 	var ctx context.Context
 
-	if err := common.ReadProtoBody(r.Body, &data); err != nil {
-		return nil, 0, 0, err
+	if err := common.ReadProtoBody(r, &data); err != nil {
+		return nil, nil, err
+	}
+
+	// secondary sessions don't start a backend
+	if data.GetSecondary() {
+		return r.Context(), &data, nil
 	}
 
 	if data.Type == common.BackendType_XRAY {
 		config, err := xray.NewXRayConfig(data.GetConfig(), data.GetExcludeInbounds())
 		if err != nil {
-			return nil, 0, 0, err
+			return nil, nil, err
 		}
 		ctx = context.WithValue(r.Context(), backend.ConfigKey{}, config)
 	} else {
-		return ctx, data.GetType(), data.GetKeepAlive(), errors.New("invalid backend type")
+		return ctx, &data, errors.New("invalid backend type")
 	}
 
 	ctx = context.WithValue(ctx, backend.UsersKey{}, data.GetUsers())
 
-	return ctx, data.GetType(), data.GetKeepAlive(), nil
+	return ctx, &data, nil
 }
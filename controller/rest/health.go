@@ -0,0 +1,23 @@
+package rest
+
+import "net/http"
+
+// probeEndpoints answers health and readiness probes before authentication and logging,
+// they expose no user or config data. It isn't used in camouflage mode, where the probes are only
+// served next to the metrics.
+func (s *Service) probeEndpoints(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			switch r.URL.Path {
+			case "/healthz":
+				s.Healthz(w, r)
+				return
+			case "/readyz":
+				s.Readyz(w, r)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,12 +3,16 @@ package rest
 import (
 	"fmt"
 	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Rexa/Gate/common"
 )
 
 func (s *Service) GetLogs(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		common.SendError(w, codes.Internal, "Streaming unsupported")
 		return
 	}
 
@@ -1,47 +1,166 @@
 package rest
 
 import (
+	"bytes"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+	"github.com/Rexa/Gate/metrics"
 )
 
+// reject answers a request that failed auth or was rate limited, with the decoy in camouflage mode.
+func (s *Service) reject(w http.ResponseWriter, r *http.Request, message string, code codes.Code) {
+	if s.decoy != nil {
+		s.decoy.ServeHTTP(w, r)
+		return
+	}
+	common.SendError(w, code, message)
+}
+
+func (s *Service) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wait, ok := s.Admit(controller.SourceIp(r.RemoteAddr)); !ok {
+			if s.decoy == nil {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			}
+			s.reject(w, r, "too many requests", codes.ResourceExhausted)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *Service) validateApiKey(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := controller.SourceIp(r.RemoteAddr)
+
 		apiKeyHeader := r.Header.Get("x-api-key")
 		if apiKeyHeader == "" {
-			http.Error(w, "missing x-api-key header", http.StatusUnauthorized)
+			s.AuthFailed(ip)
+			s.reject(w, r, "missing x-api-key header", codes.Unauthenticated)
+			return
+		}
+
+		key, err := uuid.Parse(apiKeyHeader)
+		if err != nil {
+			s.AuthFailed(ip)
+			s.reject(w, r, "invalid api key format: must be a valid UUID", codes.InvalidArgument)
 			return
 		}
 
 		// check API key
-		apiKey := s.ApiKey()
+		apiKey, ok := s.LookupApiKey(key)
+		if !ok {
+			s.AuthFailed(ip)
+			s.reject(w, r, "api key mismatch", codes.PermissionDenied)
+			return
+		}
 
-		key, err := uuid.Parse(apiKeyHeader)
-		switch {
-		case err != nil:
-			http.Error(w, "invalid api key format: must be a valid UUID", http.StatusUnprocessableEntity)
+		if !apiKey.AllowsCert(peerCertificate(r)) {
+			s.AuthFailed(ip)
+			s.reject(w, r, "api key is not bound to this client certificate", codes.PermissionDenied)
+			return
+		}
+		s.AuthSucceeded(ip)
+
+		next.ServeHTTP(w, r.WithContext(controller.WithApiKey(r.Context(), apiKey)))
+	})
+}
+
+// maxSignedBody bounds the body read to check the content digest before the request is authenticated,
+// it matches the default receive limit of the grpc server.
+const maxSignedBody = 4 << 20
+
+// validateSignature replaces validateApiKey when requests have to be signed.
+func (s *Service) validateSignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := controller.SourceIp(r.RemoteAddr)
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			if s.decoy != nil {
+				s.decoy.ServeHTTP(w, r)
+				return
+			}
+			common.SendErrorStatus(w, http.StatusRequestEntityTooLarge, codes.ResourceExhausted, fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit))
+			return
+		}
+		if err != nil {
+			common.SendError(w, codes.InvalidArgument, "failed to read request body")
 			return
-		case key != apiKey:
-			http.Error(w, "api key mismatch", http.StatusForbidden)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		apiKey, err := s.VerifySignature(controller.NewSignedRequest(r.Method, r.URL.RequestURI(), r.Header.Get))
+		if err != nil {
+			s.AuthFailed(ip)
+			s.reject(w, r, err.Error(), codes.Unauthenticated)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		if controller.ContentDigest(body) != r.Header.Get(controller.ContentDigestHeader) {
+			s.AuthFailed(ip)
+			s.reject(w, r, "request body does not match the signed content digest", codes.Unauthenticated)
+			return
+		}
+
+		if !apiKey.AllowsCert(peerCertificate(r)) {
+			s.AuthFailed(ip)
+			s.reject(w, r, "api key is not bound to this client certificate", codes.PermissionDenied)
+			return
+		}
+		s.AuthSucceeded(ip)
+
+		next.ServeHTTP(w, r.WithContext(controller.WithApiKey(r.Context(), apiKey)))
 	})
 }
 
+// peerCertificate returns the verified client certificate of the connection, if any.
+func peerCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates[0]
+	}
+	return nil
+}
+
+func (s *Service) requireScope(scope config.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !controller.Allows(r.Context(), scope) {
+				common.SendError(w, codes.PermissionDenied, fmt.Sprintf("api key is missing the %s scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func (s *Service) checkBackendMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		back := s.Backend()
 		if back == nil {
-			http.Error(w, "backend not initialized", http.StatusInternalServerError)
+			common.SendError(w, codes.Internal, "backend not initialized")
 			return
 		}
 		if !back.Started() {
-			http.Error(w, "core is not started yet", http.StatusServiceUnavailable)
+			common.SendError(w, codes.Unavailable, "core is not started yet")
 			return
 		}
 
@@ -49,15 +168,50 @@ func (s *Service) checkBackendMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func (s *Service) checkPrimaryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.IsPrimary(r.Header.Get(controller.SessionHeader), controller.SourceIp(r.RemoteAddr)) {
+			common.SendError(w, codes.PermissionDenied, "session does not control the core")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// audited writes the request to the audit log under the name and with the status code of the matching grpc method.
+// It goes after the scope check and before the backend and primary checks, like the grpc audit interceptor.
+func (s *Service) audited(method string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ctx, entry := s.BeginAudit(r.Context(), method, controller.SourceIp(r.RemoteAddr), r.Header.Get(controller.SessionHeader))
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			s.EndAudit(entry, common.HTTPToGrpcCode(ww.Status()).String())
+		})
+	}
+}
+
 func LogRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
 
 		log.Println(fmt.Sprintf("[API] New request from %s, %s, %s", r.RemoteAddr, r.Method, r.URL.Path))
 
 		next.ServeHTTP(ww, r)
 
 		log.Println(fmt.Sprintf("[API] %s, %s, %s, %d", r.RemoteAddr, r.Method, r.URL.Path, ww.Status()))
+
+		// requests rejected before routing have no pattern, the raw path would give every probe its own series
+		method := "unmatched"
+		if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+			method = r.Method + " " + pattern
+		}
+		metrics.Requests.WithLabelValues(method, strconv.Itoa(ww.Status())).Inc()
+		metrics.RequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
 	})
 }
 
@@ -69,7 +223,7 @@ func (s *Service) trackSuccessfulRequest(next http.Handler) http.Handler {
 
 		// Only track successful requests (status codes 200-299)
 		if status := ww.Status(); status >= 200 && status < 300 {
-			s.NewRequest()
+			s.NewRequest(r.Header.Get(controller.SessionHeader), controller.SourceIp(r.RemoteAddr))
 		}
 	})
 }
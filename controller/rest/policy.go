@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/controller"
+)
+
+func (s *Service) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := s.Backend().GetPolicies(r.Context())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+
+	common.SendProtoResponse(w, r, policies)
+}
+
+func (s *Service) SetPolicies(w http.ResponseWriter, r *http.Request) {
+	var policies common.Policies
+	if err := common.ReadProtoBody(r, &policies); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	if err := s.Backend().SetPolicies(r.Context(), &policies); err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+	controller.Audit(r.Context()).Changes.CoreRestarted = true
+
+	common.SendProtoResponse(w, r, &common.Empty{})
+}
@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -271,6 +273,121 @@ func TestREST_SyncUser(t *testing.T) {
 	}
 }
 
+// seedUser syncs a user so a test does not depend on the users left by the others.
+func seedUser(t *testing.T, email string) {
+	t.Helper()
+
+	user := &common.User{
+		Email:    email,
+		Inbounds: []string{"VMESS TCP NOTLS"},
+		Proxies: &common.Proxy{
+			Vmess: &common.Vmess{
+				Id: uuid.New().String(),
+			},
+		},
+	}
+	if err := sharedTestCtx.createAuthenticatedRequest("PUT", "/user/sync", user, &common.Empty{}); err != nil {
+		t.Fatalf("Failed to seed %s: %v", email, err)
+	}
+}
+
+func TestREST_SyncUsers(t *testing.T) {
+	seedUser(t, "test_user1@example.com")
+	seedUser(t, "test_user2@example.com")
+
+	users := &common.Users{
+		Users: []*common.User{
+			{
+				Email:    "test_user1@example.com",
+				Inbounds: []string{"VMESS TCP NOTLS"},
+				Proxies: &common.Proxy{
+					Vmess: &common.Vmess{
+						Id: uuid.New().String(),
+					},
+				},
+			},
+		},
+	}
+
+	var response common.SyncUsersResponse
+	if err := sharedTestCtx.createAuthenticatedRequest("PUT", "/users/sync", users, &response); err != nil {
+		t.Fatalf("Sync users request failed: %v", err)
+	}
+
+	if response.GetRemoved() == 0 {
+		t.Errorf("expected test_user2 to be removed, got %+v", response.String())
+	}
+}
+
+func TestREST_RemoveUsers(t *testing.T) {
+	seedUser(t, "test_user1@example.com")
+
+	request := &common.RemoveUsersRequest{Emails: []string{"test_user1@example.com", "unknown@example.com"}}
+
+	var response common.RemoveUsersResponse
+	if err := sharedTestCtx.createAuthenticatedRequest("PUT", "/users/remove", request, &response); err != nil {
+		t.Fatalf("Remove users request failed: %v", err)
+	}
+
+	if len(response.GetRemoved()) != 1 || response.GetRemoved()[0] != "test_user1@example.com" {
+		t.Errorf("expected test_user1@example.com to be removed, got %+v", response.String())
+	}
+	if len(response.GetUnknown()) != 1 || response.GetUnknown()[0] != "unknown@example.com" {
+		t.Errorf("expected unknown@example.com to be reported as unknown, got %+v", response.String())
+	}
+}
+
+func TestREST_SignedBodyLimit(t *testing.T) {
+	cfg := &config.Config{SignedRequests: true}
+	s := New(cfg, controller.New(cfg))
+
+	request := httptest.NewRequest(http.MethodPut, "/users/sync", bytes.NewReader(make([]byte, maxSignedBody+1)))
+	recorder := httptest.NewRecorder()
+	s.Router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected %d for an oversized unauthenticated body, got %d", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+}
+
+func TestREST_AuditsRejectedControl(t *testing.T) {
+	key := uuid.New()
+	cfg := &config.Config{ApiKey: key, AuditLogPath: t.TempDir() + "/audit.log", AuditLogMaxSize: 1, AuditLogMaxFiles: 1}
+	c := controller.New(cfg)
+	s := New(cfg, c)
+
+	request := httptest.NewRequest(http.MethodPut, "/users/sync", nil)
+	request.Header.Set("x-api-key", key.String())
+	recorder := httptest.NewRecorder()
+	s.Router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected a node without a backend to refuse the sync, got %d", recorder.Code)
+	}
+
+	audited, err := c.QueryAuditLog(&common.AuditLogRequest{Method: "SyncUsers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := audited.GetEntries(); len(entries) != 1 || entries[0].GetCode() != "Internal" {
+		t.Fatalf("expected the refused sync to be audited with the grpc code, got %v", entries)
+	}
+}
+
+func TestREST_CamouflageHidesProbes(t *testing.T) {
+	cfg := &config.Config{Camouflage: config.CamouflageNotFound}
+	s := New(cfg, controller.New(cfg))
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		recorder := httptest.NewRecorder()
+		s.Router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("expected the decoy to answer %s on a camouflaged node, got %d %q", path, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
 func TestREST_GetLogsStream(t *testing.T) {
 	reader, err := sharedTestCtx.createAuthenticatedStreamingRequest("GET", "/logs")
 	if err != nil {
@@ -303,6 +420,42 @@ func TestREST_GetSystemStats(t *testing.T) {
 		systemStats.MemTotal, systemStats.MemUsed, systemStats.CpuCores, systemStats.CpuUsage, systemStats.IncomingBandwidthSpeed, systemStats.OutgoingBandwidthSpeed)
 }
 
+func TestREST_V2_GetUsersStats(t *testing.T) {
+	var stats common.StatResponse
+	if err := sharedTestCtx.createAuthenticatedRequest("GET", "/v2/stats/users?reset=true", &common.Empty{}, &stats); err != nil {
+		t.Fatalf("Failed to get users stats: %v", err)
+	}
+
+	for _, stat := range stats.GetStats() {
+		log.Printf("Users Stat - Name: %s, Traffic: %d, Type: %s, Link: %s",
+			stat.GetName(), stat.GetValue(), stat.GetType(), stat.GetLink())
+	}
+}
+
+func TestREST_V2_OpenApi(t *testing.T) {
+	req, err := http.NewRequest("GET", sharedTestCtx.url+"/v2/openapi.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("x-api-key", apiKey.String())
+
+	resp, err := sharedTestCtx.client.Do(req)
+	if err != nil {
+		t.Fatalf("OpenAPI request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var document struct {
+		Paths map[string]any `json:"paths"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		t.Fatalf("Failed to decode OpenAPI document: %v", err)
+	}
+	if _, ok := document.Paths["/v2/stats/users/{email}"]; !ok {
+		t.Errorf("expected /v2/stats/users/{email} to be documented")
+	}
+}
+
 func TestREST_StopBackend(t *testing.T) {
 	user := &common.User{}
 	if err := sharedTestCtx.createAuthenticatedRequest("PUT", "/stop", user, &common.Empty{}); err != nil {
@@ -14,9 +14,11 @@ import (
 	"github.com/Rexa/Gate/controller"
 )
 
-func New(cfg *config.Config) *Service {
+// New creates the REST service on top of c, which can be shared with the gRPC service.
+func New(cfg *config.Config, c *controller.Controller) *Service {
 	s := &Service{
-		Controller: *controller.New(cfg),
+		Controller: c,
+		decoy:      controller.NewDecoy(cfg),
 	}
 	s.setRouter()
 	return s
@@ -26,41 +28,75 @@ func (s *Service) setRouter() {
 	router := chi.NewRouter()
 
 	// Api Handlers
+	if s.decoy == nil {
+		router.Use(s.probeEndpoints)
+	}
 	router.Use(LogRequest)
-	router.Use(s.validateApiKey)
+	router.Use(s.rateLimit)
+	if s.SigningRequired() {
+		router.Use(s.validateSignature)
+	} else {
+		router.Use(s.validateApiKey)
+	}
 	router.Use(s.trackSuccessfulRequest)
 	router.Use(middleware.Recoverer)
 
-	router.Post("/start", s.Start)
+	router.With(s.audited("Start")).Post("/start", s.Start)
 	router.Get("/info", s.Base)
+	router.With(s.requireScope(config.ScopeStats)).Get("/bans", s.GetBans)
+	router.With(s.requireScope(config.ScopeLifecycle), s.audited("ClearBans")).Put("/bans/clear", s.ClearBans)
+	router.With(s.requireScope(config.ScopeLogs)).Get("/audit", s.GetAuditLog)
+	router.Route("/v2", s.setV2Router)
+
+	router.With(s.audited("Stop"), s.checkBackendMiddleware).Put("/stop", s.Stop)
+
+	// control routes are audited before the backend and session checks so their rejections are audited too
+	control := func(scope config.Scope, method string) chi.Router {
+		return router.With(s.requireScope(scope), s.audited(method), s.checkBackendMiddleware, s.checkPrimaryMiddleware)
+	}
+	control(config.ScopeUsers, "SyncUser").Put("/user/sync", s.SyncUser)
+	control(config.ScopeUsers, "SyncUsers").Put("/users/sync", s.SyncUsers)
+	control(config.ScopeUsers, "RemoveUsers").Put("/users/remove", s.RemoveUsers)
+	control(config.ScopeLifecycle, "SetPolicies").Put("/policies", s.SetPolicies)
 
 	router.Group(func(private chi.Router) {
 		private.Use(s.checkBackendMiddleware)
 
-		private.Put("/stop", s.Stop)
-		private.Get("/logs", s.GetLogs)
+		private.With(s.requireScope(config.ScopeLogs)).Get("/logs", s.GetLogs)
 		// stats api
 		private.Route("/stats", func(statsGroup chi.Router) {
+			statsGroup.Use(s.requireScope(config.ScopeStats))
+
 			statsGroup.Get("/", s.GetStats)
 			statsGroup.Get("/user/online", s.GetUserOnlineStat)
 			statsGroup.Get("/user/online_ip", s.GetUserOnlineIpListStats)
 			statsGroup.Get("/backend", s.GetBackendStats)
 			statsGroup.Get("/system", s.GetSystemStats)
 		})
-		private.Put("/user/sync", s.SyncUser)
-		private.Put("/users/sync", s.SyncUsers)
+		private.Group(func(read chi.Router) {
+			read.Use(s.requireScope(config.ScopeStats))
+
+			read.Get("/users", s.ListUsers)
+			read.Get("/user", s.GetUser)
+			read.Get("/users/restricted", s.GetRestrictedUsers)
+			read.Get("/users/expirations", s.GetUpcomingExpirations)
+			read.Get("/users/enforcement_events", s.GetEnforcementEvents)
+			read.Get("/policies", s.GetPolicies)
+		})
 	})
 
 	s.Router = router
 }
 
 type Service struct {
-	controller.Controller
+	*controller.Controller
 	Router chi.Router
+	// decoy answers requests that fail auth in camouflage mode.
+	decoy http.Handler
 }
 
 func StartHttpListener(tlsConfig *tls.Config, addr string, cfg *config.Config) (func(ctx context.Context) error, controller.Service, error) {
-	s := New(cfg)
+	s := New(cfg, controller.New(cfg))
 
 	httpServer := &http.Server{
 		Addr:      addr,
@@ -1,79 +1,83 @@
 package rest
 
 import (
-	"google.golang.org/grpc/status"
 	"net/http"
 
+	"google.golang.org/grpc/codes"
+
 	"github.com/Rexa/Gate/common"
 )
 
 func (s *Service) GetStats(w http.ResponseWriter, r *http.Request) {
 	var request common.StatRequest
-	if err := common.ReadProtoBody(r.Body, &request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
 
-	stats, err := s.Backend().GetStats(r.Context(), &request)
+	s.getStats(w, r, &request)
+}
+
+func (s *Service) getStats(w http.ResponseWriter, r *http.Request, request *common.StatRequest) {
+	stats, err := s.Backend().GetStats(r.Context(), request)
 	if err != nil {
-		err = common.InterceptNotFound(err)
-		st, _ := status.FromError(err)
-		httpCode := common.GrpcCodeToHTTP(st.Code())
-		http.Error(w, err.Error(), httpCode)
+		common.SendStatusError(w, common.InterceptNotFound(err))
 		return
 	}
 
-	common.SendProtoResponse(w, stats)
+	common.SendProtoResponse(w, r, stats)
 }
 
 func (s *Service) GetUserOnlineStat(w http.ResponseWriter, r *http.Request) {
 	var request common.StatRequest
-	if err := common.ReadProtoBody(r.Body, &request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
 
+	s.getUserOnlineStat(w, r, &request)
+}
+
+func (s *Service) getUserOnlineStat(w http.ResponseWriter, r *http.Request, request *common.StatRequest) {
 	stats, err := s.Backend().GetUserOnlineStats(r.Context(), request.GetName())
 	if err != nil {
-		err = common.InterceptNotFound(err)
-		st, _ := status.FromError(err)
-		httpCode := common.GrpcCodeToHTTP(st.Code())
-		http.Error(w, err.Error(), httpCode)
+		common.SendStatusError(w, common.InterceptNotFound(err))
 		return
 	}
 
-	common.SendProtoResponse(w, stats)
+	common.SendProtoResponse(w, r, stats)
 }
 
 func (s *Service) GetUserOnlineIpListStats(w http.ResponseWriter, r *http.Request) {
 	var request common.StatRequest
-	if err := common.ReadProtoBody(r.Body, &request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
 
+	s.getUserOnlineIpListStats(w, r, &request)
+}
+
+func (s *Service) getUserOnlineIpListStats(w http.ResponseWriter, r *http.Request, request *common.StatRequest) {
 	stats, err := s.Backend().GetUserOnlineIpListStats(r.Context(), request.GetName())
 	if err != nil {
-		err = common.InterceptNotFound(err)
-		st, _ := status.FromError(err)
-		httpCode := common.GrpcCodeToHTTP(st.Code())
-		http.Error(w, err.Error(), httpCode)
+		common.SendStatusError(w, common.InterceptNotFound(err))
 		return
 	}
 
-	common.SendProtoResponse(w, stats)
+	common.SendProtoResponse(w, r, stats)
 }
 
 func (s *Service) GetBackendStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := s.Backend().GetSysStats(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		common.SendError(w, codes.Internal, err.Error())
 		return
 	}
 
-	common.SendProtoResponse(w, stats)
+	common.SendProtoResponse(w, r, stats)
 }
 
-func (s *Service) GetSystemStats(w http.ResponseWriter, _ *http.Request) {
-	common.SendProtoResponse(w, s.SystemStats())
+func (s *Service) GetSystemStats(w http.ResponseWriter, r *http.Request) {
+	common.SendProtoResponse(w, r, s.SystemStats())
 }
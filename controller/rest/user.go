@@ -1,75 +1,167 @@
 package rest
 
 import (
-	"io"
 	"log"
 	"net/http"
 
-	"google.golang.org/protobuf/proto"
+	"google.golang.org/grpc/codes"
 
 	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/controller"
 )
 
 func (s *Service) SyncUser(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+	user := &common.User{}
+	if err := common.ReadProtoBody(r, user); err != nil {
+		common.SendError(w, codes.InvalidArgument, "Failed to decode user")
+		return
+	}
+
+	s.syncUser(w, r, user)
+}
+
+func (s *Service) syncUser(w http.ResponseWriter, r *http.Request, user *common.User) {
+	log.Printf("Got user: %v", user.GetEmail())
+	changes := controller.Audit(r.Context()).Changes
+	changes.Users = append(changes.Users, user.GetEmail())
+
+	restarted, err := s.Backend().SyncUser(r.Context(), user)
+	if restarted {
+		changes.CoreRestarted = true
+	}
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		log.Printf("Error syncing user: %v", err)
+		common.SendError(w, codes.Internal, err.Error())
 		return
 	}
-	defer r.Body.Close()
 
-	user := &common.User{}
-	if err = proto.Unmarshal(body, user); err != nil {
-		http.Error(w, "Failed to decode user", http.StatusBadRequest)
+	common.SendProtoResponse(w, r, &common.Empty{})
+}
+
+func (s *Service) SyncUsers(w http.ResponseWriter, r *http.Request) {
+	users := &common.Users{}
+	if err := common.ReadProtoBody(r, users); err != nil {
+		common.SendError(w, codes.InvalidArgument, "Failed to decode user")
 		return
 	}
 
-	if user == nil {
-		http.Error(w, "no user received", http.StatusBadRequest)
+	syncResponse, err := s.Backend().SyncUsers(r.Context(), users.GetUsers())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
 		return
 	}
+	controller.AuditSync(r.Context(), syncResponse)
 
-	log.Printf("Got user: %v", user.GetEmail())
+	common.SendProtoResponse(w, r, syncResponse)
+}
 
-	if err = s.Backend().SyncUser(r.Context(), user); err != nil {
-		log.Printf("Error syncing user: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func (s *Service) RemoveUsers(w http.ResponseWriter, r *http.Request) {
+	var request common.RemoveUsersRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
 
-	response, _ := proto.Marshal(&common.Empty{})
+	s.removeUsers(w, r, &request)
+}
 
-	w.Header().Set("Content-Type", "application/x-protobuf")
-	if _, err = w.Write(response); err != nil {
-		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+func (s *Service) removeUsers(w http.ResponseWriter, r *http.Request, request *common.RemoveUsersRequest) {
+	if len(request.GetEmails()) == 0 {
+		common.SendError(w, codes.InvalidArgument, "at least one email is required")
 		return
 	}
+
+	response, err := s.Backend().RemoveUsers(r.Context(), request.GetEmails())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+	controller.AuditRemove(r.Context(), response)
+
+	common.SendProtoResponse(w, r, response)
 }
 
-func (s *Service) SyncUsers(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+func (s *Service) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.Backend().ListUsers(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		common.SendError(w, codes.Internal, err.Error())
 		return
 	}
-	defer r.Body.Close()
 
-	users := &common.Users{}
-	if err = proto.Unmarshal(body, users); err != nil {
-		http.Error(w, "Failed to decode user", http.StatusBadRequest)
+	common.SendProtoResponse(w, r, users)
+}
+
+func (s *Service) GetUser(w http.ResponseWriter, r *http.Request) {
+	var request common.UserRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	s.getUser(w, r, &request)
+}
+
+func (s *Service) getUser(w http.ResponseWriter, r *http.Request, request *common.UserRequest) {
+	if request.GetEmail() == "" {
+		common.SendError(w, codes.InvalidArgument, "email is required")
+		return
+	}
+
+	user, err := s.Backend().GetUser(r.Context(), request.GetEmail())
+	if err != nil {
+		common.SendStatusError(w, err)
+		return
+	}
+
+	common.SendProtoResponse(w, r, user)
+}
+
+func (s *Service) GetRestrictedUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.Backend().GetRestrictedUsers(r.Context())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+
+	common.SendProtoResponse(w, r, users)
+}
+
+func (s *Service) GetUpcomingExpirations(w http.ResponseWriter, r *http.Request) {
+	var request common.ExpirationsRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
 
-	if err = s.Backend().SyncUsers(r.Context(), users.GetUsers()); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	s.getUpcomingExpirations(w, r, &request)
+}
+
+func (s *Service) getUpcomingExpirations(w http.ResponseWriter, r *http.Request, request *common.ExpirationsRequest) {
+	expirations, err := s.Backend().GetUpcomingExpirations(r.Context(), request.GetWithin())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
 		return
 	}
 
-	response, _ := proto.Marshal(&common.Empty{})
+	common.SendProtoResponse(w, r, expirations)
+}
 
-	w.Header().Set("Content-Type", "application/x-protobuf")
-	if _, err = w.Write(response); err != nil {
-		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+func (s *Service) GetEnforcementEvents(w http.ResponseWriter, r *http.Request) {
+	var request common.EnforcementEventsRequest
+	if err := common.ReadProtoBody(r, &request); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
 		return
 	}
+
+	s.getEnforcementEvents(w, r, &request)
+}
+
+func (s *Service) getEnforcementEvents(w http.ResponseWriter, r *http.Request, request *common.EnforcementEventsRequest) {
+	events, err := s.Backend().GetEnforcementEvents(r.Context(), request.GetSince())
+	if err != nil {
+		common.SendError(w, codes.Internal, err.Error())
+		return
+	}
+
+	common.SendProtoResponse(w, r, events)
 }
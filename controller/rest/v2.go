@@ -0,0 +1,232 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+	"github.com/Rexa/Gate/openapi"
+)
+
+// route is a v2 endpoint, the same table registers the handlers and documents them.
+type route struct {
+	openapi.Operation
+	handler http.HandlerFunc
+	scope   config.Scope
+	// backend requires a started core, primary requires the session that controls it.
+	backend bool
+	primary bool
+	// audit is the grpc method the request is audited as.
+	audit string
+}
+
+func (s *Service) v2Routes() []route {
+	return []route{
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/info", Summary: "Node and core versions", Response: &common.BaseInfoResponse{}},
+			handler: s.Base},
+		{Operation: openapi.Operation{Method: http.MethodPost, Path: "/core", Summary: "Start the core, or attach a secondary session", Body: &common.Backend{}, Response: &common.BaseInfoResponse{},
+			Description: fmt.Sprintf("Starting the core requires the %s scope, attaching a secondary session doesn't.", config.ScopeLifecycle)},
+			handler: s.Start, audit: "Start"},
+		{Operation: openapi.Operation{Method: http.MethodDelete, Path: "/core", Summary: "Stop the core, or end a secondary session", Response: &common.Empty{},
+			Description: fmt.Sprintf("Stopping the core requires the %s scope, ending a secondary session doesn't.", config.ScopeLifecycle)},
+			handler: s.Stop, backend: true, audit: "Stop"},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/logs", Summary: "Stream the core logs", EventStream: true},
+			handler: s.GetLogs, scope: config.ScopeLogs, backend: true},
+
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/inbounds", Summary: "Traffic of every inbound", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_Inbounds, ""), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/inbounds/{tag}", Summary: "Traffic of an inbound", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_Inbound, "tag"), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/outbounds", Summary: "Traffic of every outbound", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_Outbounds, ""), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/outbounds/{tag}", Summary: "Traffic of an outbound", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_Outbound, "tag"), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/users", Summary: "Traffic of every user", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_UsersStat, ""), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/users/{email}", Summary: "Traffic of a user", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+			handler: s.StatsV2(common.StatType_UserStat, "email"), scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/users/{email}/online", Summary: "Online connections of a user", Response: &common.OnlineStatResponse{}},
+			handler: s.GetUserOnlineStatV2, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/users/{email}/online_ips", Summary: "Online ips of a user", Response: &common.StatsOnlineIpListResponse{}},
+			handler: s.GetUserOnlineIpListStatsV2, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/backend", Summary: "Runtime stats of the core", Response: &common.BackendStatsResponse{}},
+			handler: s.GetBackendStats, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/stats/system", Summary: "Memory, cpu and bandwidth of the host", Response: &common.SystemStatsResponse{}},
+			handler: s.GetSystemStats, scope: config.ScopeStats, backend: true},
+
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/users", Summary: "Users loaded in the core", Response: &common.LoadedUsersResponse{}},
+			handler: s.ListUsers, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodPut, Path: "/users", Summary: "Replace every user", Body: &common.Users{}, Response: &common.SyncUsersResponse{}},
+			handler: s.SyncUsers, scope: config.ScopeUsers, backend: true, primary: true, audit: "SyncUsers"},
+		{Operation: openapi.Operation{Method: http.MethodDelete, Path: "/users", Summary: "Remove users", Query: &common.RemoveUsersRequest{}, Response: &common.RemoveUsersResponse{}},
+			handler: s.RemoveUsersV2, scope: config.ScopeUsers, backend: true, primary: true, audit: "RemoveUsers"},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/users/restricted", Summary: "Users restricted by their limits", Response: &common.RestrictedUsersResponse{}},
+			handler: s.GetRestrictedUsers, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/users/expirations", Summary: "Users expiring soon", Query: &common.ExpirationsRequest{}, Response: &common.ExpirationsResponse{}},
+			handler: s.GetUpcomingExpirationsV2, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/users/enforcement_events", Summary: "Restrictions applied and released", Query: &common.EnforcementEventsRequest{}, Response: &common.EnforcementEventsResponse{}},
+			handler: s.GetEnforcementEventsV2, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/users/{email}", Summary: "A user loaded in the core", Response: &common.LoadedUser{}},
+			handler: s.GetUserV2, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodPut, Path: "/users/{email}", Summary: "Add or update a user", Body: &common.User{}, Response: &common.Empty{}},
+			handler: s.SyncUserV2, scope: config.ScopeUsers, backend: true, primary: true, audit: "SyncUser"},
+		{Operation: openapi.Operation{Method: http.MethodDelete, Path: "/users/{email}", Summary: "Remove a user", Response: &common.RemoveUsersResponse{}},
+			handler: s.RemoveUsersV2, scope: config.ScopeUsers, backend: true, primary: true, audit: "RemoveUsers"},
+
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/policies", Summary: "Policies of the user levels", Response: &common.Policies{}},
+			handler: s.GetPolicies, scope: config.ScopeStats, backend: true},
+		{Operation: openapi.Operation{Method: http.MethodPut, Path: "/policies", Summary: "Replace the policies of the user levels", Body: &common.Policies{}, Response: &common.Empty{}},
+			handler: s.SetPolicies, scope: config.ScopeLifecycle, backend: true, primary: true, audit: "SetPolicies"},
+
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/bans", Summary: "Source ips with recent auth failures", Response: &common.BansResponse{}},
+			handler: s.GetBans, scope: config.ScopeStats},
+		{Operation: openapi.Operation{Method: http.MethodDelete, Path: "/bans", Summary: "Lift bans, of every ip when none are given", Query: &common.ClearBansRequest{}, Response: &common.ClearBansResponse{}},
+			handler: s.ClearBansV2, scope: config.ScopeLifecycle, audit: "ClearBans"},
+		{Operation: openapi.Operation{Method: http.MethodGet, Path: "/audit", Summary: "Control-plane operations from the audit log", Query: &common.AuditLogRequest{}, Response: &common.AuditLogResponse{}},
+			handler: s.GetAuditLogV2, scope: config.ScopeLogs},
+	}
+}
+
+// setV2Router registers the v2 routes under router, reads take their parameters from the path and query string.
+func (s *Service) setV2Router(router chi.Router) {
+	routes := s.v2Routes()
+
+	operations := make([]openapi.Operation, 0, len(routes))
+	for _, rt := range routes {
+		var middlewares chi.Middlewares
+		if rt.scope != "" {
+			middlewares = append(middlewares, s.requireScope(rt.scope))
+			if rt.Description == "" {
+				rt.Description = fmt.Sprintf("Requires the %s scope.", rt.scope)
+			}
+		}
+		if rt.audit != "" {
+			middlewares = append(middlewares, s.audited(rt.audit))
+		}
+		if rt.backend {
+			middlewares = append(middlewares, s.checkBackendMiddleware)
+		}
+		if rt.primary {
+			middlewares = append(middlewares, s.checkPrimaryMiddleware)
+		}
+		router.With(middlewares...).Method(rt.Method, rt.Path, rt.handler)
+
+		rt.Path = "/v2" + rt.Path
+		operations = append(operations, rt.Operation)
+	}
+
+	document, err := openapi.Document(openapi.Info{
+		Title:   "Gate node API",
+		Version: controller.GateVersion,
+		Description: "Bodies are protobuf or json, picked by the Content-Type and Accept headers. " +
+			"Nodes running with signed requests take the x-api-key-name, x-timestamp, x-nonce, x-content-sha256 " +
+			"and x-signature headers instead of the api key.",
+		ApiKeyHeader: "x-api-key",
+	}, operations)
+	if err != nil {
+		log.Fatalf("failed to build the openapi document: %v", err)
+	}
+	router.Method(http.MethodGet, "/openapi.json", openapi.Handler(document))
+}
+
+// readQuery decodes the request message of a v2 read from the query string.
+func readQuery(w http.ResponseWriter, r *http.Request, message proto.Message) bool {
+	if err := common.ReadProtoQuery(r.URL.Query(), message); err != nil {
+		common.SendError(w, codes.InvalidArgument, err.Error())
+		return false
+	}
+	return true
+}
+
+// StatsV2 serves the stats of one type, param names the route parameter that picks a single inbound, outbound or user.
+func (s *Service) StatsV2(statType common.StatType, param string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request common.StatRequest
+		if !readQuery(w, r, &request) {
+			return
+		}
+
+		request.Type, request.Name = statType, ""
+		if param != "" {
+			request.Name = chi.URLParam(r, param)
+		}
+		s.getStats(w, r, &request)
+	}
+}
+
+func (s *Service) GetUserOnlineStatV2(w http.ResponseWriter, r *http.Request) {
+	s.getUserOnlineStat(w, r, &common.StatRequest{Name: chi.URLParam(r, "email")})
+}
+
+func (s *Service) GetUserOnlineIpListStatsV2(w http.ResponseWriter, r *http.Request) {
+	s.getUserOnlineIpListStats(w, r, &common.StatRequest{Name: chi.URLParam(r, "email")})
+}
+
+func (s *Service) GetUserV2(w http.ResponseWriter, r *http.Request) {
+	s.getUser(w, r, &common.UserRequest{Email: chi.URLParam(r, "email")})
+}
+
+// SyncUserV2 takes the email from the path, a body carrying a different one is rejected.
+func (s *Service) SyncUserV2(w http.ResponseWriter, r *http.Request) {
+	user := &common.User{}
+	if err := common.ReadProtoBody(r, user); err != nil {
+		common.SendError(w, codes.InvalidArgument, "Failed to decode user")
+		return
+	}
+
+	email := chi.URLParam(r, "email")
+	if user.GetEmail() != "" && user.GetEmail() != email {
+		common.SendError(w, codes.InvalidArgument, "email of the user does not match the path")
+		return
+	}
+	user.Email = email
+
+	s.syncUser(w, r, user)
+}
+
+// RemoveUsersV2 removes the user in the path, or the ones given as emails query parameters.
+func (s *Service) RemoveUsersV2(w http.ResponseWriter, r *http.Request) {
+	var request common.RemoveUsersRequest
+	if email := chi.URLParam(r, "email"); email != "" {
+		request.Emails = []string{email}
+	} else if !readQuery(w, r, &request) {
+		return
+	}
+
+	s.removeUsers(w, r, &request)
+}
+
+func (s *Service) GetUpcomingExpirationsV2(w http.ResponseWriter, r *http.Request) {
+	var request common.ExpirationsRequest
+	if readQuery(w, r, &request) {
+		s.getUpcomingExpirations(w, r, &request)
+	}
+}
+
+func (s *Service) GetEnforcementEventsV2(w http.ResponseWriter, r *http.Request) {
+	var request common.EnforcementEventsRequest
+	if readQuery(w, r, &request) {
+		s.getEnforcementEvents(w, r, &request)
+	}
+}
+
+func (s *Service) ClearBansV2(w http.ResponseWriter, r *http.Request) {
+	var request common.ClearBansRequest
+	if readQuery(w, r, &request) {
+		s.clearBans(w, r, &request)
+	}
+}
+
+func (s *Service) GetAuditLogV2(w http.ResponseWriter, r *http.Request) {
+	var request common.AuditLogRequest
+	if readQuery(w, r, &request) {
+		s.getAuditLog(w, r, &request)
+	}
+}
@@ -0,0 +1,18 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func (s *Service) GetAuditLog(_ context.Context, request *common.AuditLogRequest) (*common.AuditLogResponse, error) {
+	response, err := s.QueryAuditLog(request)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read audit log: %v", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,15 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func (s *Service) GetBans(_ context.Context, _ *common.Empty) (*common.BansResponse, error) {
+	return s.Bans(), nil
+}
+
+func (s *Service) ClearBans(_ context.Context, request *common.ClearBansRequest) (*common.ClearBansResponse, error) {
+	return &common.ClearBansResponse{Cleared: s.Controller.ClearBans(request.GetIps())}, nil
+}
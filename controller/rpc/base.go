@@ -9,13 +9,16 @@ import (
 	"github.com/Rexa/Gate/backend"
 	"github.com/Rexa/Gate/backend/xray"
 	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func (s *Service) Start(ctx context.Context, detail *common.Backend) (*common.BaseInfoResponse, error) {
-	ctx, err := s.detectBackend(ctx, detail)
-	if err != nil {
-		return nil, err
+	if !detail.GetSecondary() && !controller.Allows(ctx, config.ScopeLifecycle) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key is missing the %s scope", config.ScopeLifecycle)
 	}
 
 	clientIP := ""
@@ -35,22 +38,68 @@ func (s *Service) Start(ctx context.Context, detail *common.Backend) (*common.Ba
 		}
 	}
 
+	if detail.GetSecondary() {
+		response := s.BaseInfoResponse()
+		response.SessionId = s.Attach(clientIP, detail.GetKeepAlive())
+		controller.Audit(ctx).SessionId = response.SessionId
+		return response, nil
+	}
+
+	ctx, err := s.detectBackend(ctx, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash, err := controller.ConfigHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	controller.Audit(ctx).Changes.ConfigHash = configHash
+
+	takenOver, err := s.TakeOver(ctx, configHash, detail.GetUsers())
+	if err != nil {
+		return nil, err
+	}
+	if takenOver {
+		log.Println("New connection from ", clientIP, " took over the running core, config is unchanged.")
+		response := s.BaseInfoResponse()
+		response.SessionId = s.Connect(clientIP, detail.GetKeepAlive(), detail.GetDetached())
+		controller.Audit(ctx).SessionId = response.SessionId
+		return response, nil
+	}
+
 	if s.Backend() != nil {
 		log.Println("New connection from ", clientIP, " core control access was taken away from previous client.")
 		s.Disconnect()
 	}
 
-	if err = s.StartBackend(ctx, detail.GetType()); err != nil {
+	if err = s.StartBackend(ctx, detail.GetType(), configHash); err != nil {
 		return nil, err
 	}
 
-	s.Connect(clientIP, detail.GetKeepAlive())
+	response := s.BaseInfoResponse()
+	response.SessionId = s.Connect(clientIP, detail.GetKeepAlive(), detail.GetDetached())
+	controller.Audit(ctx).Changes.CoreRestarted = true
+	controller.Audit(ctx).SessionId = response.SessionId
 
-	return s.BaseInfoResponse(), nil
+	return response, nil
 }
 
-func (s *Service) Stop(_ context.Context, _ *common.Empty) (*common.Empty, error) {
+func (s *Service) Stop(ctx context.Context, _ *common.Empty) (*common.Empty, error) {
+	if id := sessionID(ctx); !s.IsPrimary(id, sourceIp(ctx)) {
+		if id == "" {
+			return nil, status.Errorf(codes.PermissionDenied, "session does not control the core")
+		}
+		s.EndSession(id)
+		return nil, nil
+	}
+
+	if !controller.Allows(ctx, config.ScopeLifecycle) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key is missing the %s scope", config.ScopeLifecycle)
+	}
+
 	s.Disconnect()
+	controller.Audit(ctx).Changes.CoreStopped = true
 	return nil, nil
 }
 
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+)
+
+// camouflageHandler serves gRPC through net/http, so requests that fail auth are answered by the decoy
+// the way an ordinary HTTP/2 server would instead of with a gRPC status.
+// Health probes and CORS preflights need an api key here too, an unauthenticated answer would give the node away.
+// With web set, gRPC-Web and Connect calls are passed on to be translated by next.
+func camouflageHandler(s *Service, next http.Handler, decoy http.Handler, web bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := controller.SourceIp(r.RemoteAddr)
+		if _, ok := s.Admit(ip); !ok {
+			decoy.ServeHTTP(w, r)
+			return
+		}
+
+		var cert *x509.Certificate
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			cert = r.TLS.PeerCertificates[0]
+		}
+
+		// the content digest of signed calls is checked against the message by the interceptors
+		var apiKey *config.ApiKey
+		var err error
+		if s.SigningRequired() {
+			apiKey, err = s.VerifySignature(controller.NewSignedRequest(r.Method, r.URL.Path, r.Header.Get))
+		} else {
+			var key uuid.UUID
+			if key, err = uuid.Parse(r.Header.Get("x-api-key")); err == nil {
+				if apiKey, _ = s.LookupApiKey(key); apiKey == nil {
+					err = errors.New("api key mismatch")
+				}
+			}
+		}
+		if err != nil || !apiKey.AllowsCert(cert) {
+			s.AuthFailed(ip)
+			decoy.ServeHTTP(w, r)
+			return
+		}
+		s.AuthSucceeded(ip)
+
+		isGrpc := r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+		if _, _, isWeb := webProtocolOf(r); !isGrpc && !(web && isWeb) {
+			decoy.ServeHTTP(w, r)
+			return
+		}
+
+		// the interceptors find the key in the context and don't authenticate or count the request again
+		next.ServeHTTP(w, r.WithContext(controller.WithApiKey(r.Context(), apiKey)))
+	})
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	healthMethodPrefix = "/grpc.health.v1.Health/"
+	// gateService reports readiness, the empty service name reports liveness.
+	gateService = "service.GateService"
+)
+
+// isHealthMethod reports whether the call is a health probe, probes need no api key outside camouflage mode.
+func isHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, healthMethodPrefix)
+}
+
+// healthServer implements grpc.health.v1 without exposing user or config data.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	s *Service
+}
+
+func (h *healthServer) status(service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	switch service {
+	case "":
+		return healthpb.HealthCheckResponse_SERVING, nil
+	case gateService:
+		if h.s.Ready() != nil {
+			return healthpb.HealthCheckResponse_NOT_SERVING, nil
+		}
+		return healthpb.HealthCheckResponse_SERVING, nil
+	default:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %q", service)
+	}
+}
+
+func (h *healthServer) Check(_ context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, err := h.status(request.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+func (h *healthServer) List(_ context.Context, _ *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	response := &healthpb.HealthListResponse{Statuses: make(map[string]*healthpb.HealthCheckResponse)}
+	for _, service := range []string{"", gateService} {
+		servingStatus, _ := h.status(service)
+		response.Statuses[service] = &healthpb.HealthCheckResponse{Status: servingStatus}
+	}
+	return response, nil
+}
+
+// Watch sends the status of the service whenever it changes, readiness is checked every few seconds.
+func (h *healthServer) Watch(request *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		// unknown services are reported as such instead of failing the watch, as the spec asks
+		servingStatus, _ := h.status(request.GetService())
+		if servingStatus != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			last = servingStatus
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
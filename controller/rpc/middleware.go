@@ -2,44 +2,161 @@ package rpc
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Rexa/Gate/config"
+	"github.com/Rexa/Gate/controller"
+	"github.com/Rexa/Gate/metrics"
 )
 
-func validateApiKey(ctx context.Context, s *Service) error {
+// peerCertificate returns the verified client certificate of the connection, if any.
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return nil
+	}
+	return tlsInfo.State.PeerCertificates[0]
+}
+
+// sourceIp returns the ip the request came from.
+func sourceIp(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return controller.SourceIp(p.Addr.String())
+	}
+	return ""
+}
+
+func rateLimit(ctx context.Context, s *Service) error {
+	if wait, ok := s.Admit(sourceIp(ctx)); !ok {
+		return status.Errorf(codes.ResourceExhausted, "too many requests, retry in %s", wait.Round(time.Second))
+	}
+	return nil
+}
+
+// validateApiKey authenticates the call, req is the request message of unary calls and nil for streams.
+func validateApiKey(ctx context.Context, s *Service, method string, req interface{}) (context.Context, error) {
+	ip := sourceIp(ctx)
+
+	// in camouflage mode the request is authenticated before it reaches the grpc server
+	apiKey, authenticated := controller.ApiKeyFrom(ctx)
+	if !authenticated {
+		if err := rateLimit(ctx, s); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if apiKey, err = authenticate(ctx, s, method); err != nil {
+			s.AuthFailed(ip)
+			return nil, err
+		}
+	}
+
+	if s.SigningRequired() {
+		// the signature covers a single message, streamed control messages would go unsigned
+		if req == nil && controlMethods[method] {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s streams can't be signed, use SyncUsers instead", methodName(method))
+		}
+		if err := checkContentDigest(ctx, req); err != nil {
+			s.AuthFailed(ip)
+			return nil, err
+		}
+	}
+	s.AuthSucceeded(ip)
+
+	if scope, ok := methodScopes[method]; ok && !apiKey.Allows(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key %s is missing the %s scope", apiKey.Name, scope)
+	}
+
+	return controller.WithApiKey(ctx, apiKey), nil
+}
+
+// authenticate finds the api key of the request, every error it returns counts as an auth failure.
+func authenticate(ctx context.Context, s *Service, method string) (*config.ApiKey, error) {
 	// Extract metadata
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Errorf(codes.Unauthenticated, "missing metadata")
+		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+	}
+
+	if s.SigningRequired() {
+		apiKey, err := s.VerifySignature(controller.NewSignedRequest(http.MethodPost, method, metadataGetter(md)))
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+		if !apiKey.AllowsCert(peerCertificate(ctx)) {
+			return nil, status.Errorf(codes.PermissionDenied, "api key is not bound to this client certificate")
+		}
+		return apiKey, nil
 	}
 
 	// Extract x-api-key header
 	apiKeys, ok := md["x-api-key"]
 	if !ok || len(apiKeys) == 0 {
-		return status.Errorf(codes.Unauthenticated, "missing x-api-key header")
+		return nil, status.Errorf(codes.Unauthenticated, "missing x-api-key header")
 	}
 
 	// Get the first key (there should typically be only one)
 	apiKeyHeader := apiKeys[0]
 
-	apiKey := s.ApiKey()
 	key, err := uuid.Parse(apiKeyHeader)
-	switch {
-	case err != nil:
-		return status.Errorf(codes.InvalidArgument, "invalid api key format: must be a valid UUID")
-	case key != apiKey:
-		return status.Errorf(codes.PermissionDenied, "api key mismatch")
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid api key format: must be a valid UUID")
+	}
+
+	apiKey, ok := s.LookupApiKey(key)
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "api key mismatch")
 	}
 
+	if !apiKey.AllowsCert(peerCertificate(ctx)) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key is not bound to this client certificate")
+	}
+
+	return apiKey, nil
+}
+
+func metadataGetter(md metadata.MD) func(string) string {
+	return func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+}
+
+// checkContentDigest compares the signed content digest with the request message.
+func checkContentDigest(ctx context.Context, req interface{}) error {
+	var body []byte
+	if message, ok := req.(proto.Message); ok {
+		var err error
+		if body, err = (proto.MarshalOptions{Deterministic: true}).Marshal(message); err != nil {
+			return status.Errorf(codes.Internal, "failed to marshal request: %v", err)
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if controller.ContentDigest(body) != metadataGetter(md)(controller.ContentDigestHeader) {
+		return status.Errorf(codes.Unauthenticated, "request does not match the signed content digest")
+	}
 	return nil
 }
 
@@ -50,7 +167,8 @@ func validateApiKeyMiddleware(s *Service) grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		if err := validateApiKey(ctx, s); err != nil {
+		ctx, err := validateApiKey(ctx, s, info.FullMethod, req)
+		if err != nil {
 			return nil, err
 		}
 
@@ -66,11 +184,63 @@ func validateApiKeyStreamMiddleware(s *Service) grpc.StreamServerInterceptor {
 		handler grpc.StreamHandler,
 	) error {
 		// Use common session validation logic
-		if err := validateApiKey(ss.Context(), s); err != nil {
+		ctx, err := validateApiKey(ss.Context(), s, info.FullMethod, nil)
+		if err != nil {
 			log.Println("invalid api key stream:", err)
 			return err
 		}
 
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
+
+// sessionID returns the session id sent in the request metadata, if any.
+func sessionID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if ids := md.Get(controller.SessionHeader); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+func checkPrimarySession(ctx context.Context, s *Service) error {
+	if !s.IsPrimary(sessionID(ctx), sourceIp(ctx)) {
+		return status.Errorf(codes.PermissionDenied, "session does not control the core")
+	}
+	return nil
+}
+
+func CheckPrimaryMiddleware(s *Service) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := checkPrimarySession(ctx, s); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func CheckPrimaryStreamMiddleware(s *Service) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := checkPrimarySession(ss.Context(), s); err != nil {
+			return err
+		}
+
 		return handler(srv, ss)
 	}
 }
@@ -134,6 +304,11 @@ func logRequest(ctx context.Context, method string, err error) {
 	}
 }
 
+func recordMetrics(method string, start time.Time, err error) {
+	metrics.Requests.WithLabelValues(methodName(method), status.Code(err).String()).Inc()
+	metrics.RequestDuration.WithLabelValues(methodName(method)).Observe(time.Since(start).Seconds())
+}
+
 func LoggingInterceptor(s *Service) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -141,15 +316,18 @@ func LoggingInterceptor(s *Service) grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
+		start := time.Now()
+
 		// Handle the request
 		resp, err := handler(ctx, req)
 
 		// Log the request
 		logRequest(ctx, info.FullMethod, err)
+		recordMetrics(info.FullMethod, start, err)
 
 		// Track successful requests
 		if err == nil {
-			s.NewRequest()
+			s.NewRequest(sessionID(ctx), sourceIp(ctx))
 		}
 
 		return resp, err
@@ -169,21 +347,62 @@ func LoggingStreamInterceptor(s *Service) grpc.StreamServerInterceptor {
 		}
 		log.Printf("Trying To Open Stream Connection, IP: %s, Method: %s,", clientIP, strings.TrimPrefix(info.FullMethod, "/service.GateService/"))
 
+		start := time.Now()
+
 		// Handle the request
 		err := handler(srv, ss)
 
 		// Log the request
 		logRequest(ss.Context(), info.FullMethod, err)
+		recordMetrics(info.FullMethod, start, err)
 
 		// Track successful requests
 		if err == nil {
-			s.NewRequest()
+			s.NewRequest(sessionID(ss.Context()), sourceIp(ss.Context()))
 		}
 
 		return err
 	}
 }
 
+func auditInterceptor(s *Service) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, entry := s.BeginAudit(ctx, methodName(info.FullMethod), sourceIp(ctx), sessionID(ctx))
+
+		resp, err := handler(ctx, req)
+
+		s.EndAudit(entry, status.Code(err).String())
+		return resp, err
+	}
+}
+
+func auditStreamInterceptor(s *Service) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, entry := s.BeginAudit(ss.Context(), methodName(info.FullMethod), sourceIp(ss.Context()), sessionID(ss.Context()))
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		err := handler(srv, wrapped)
+
+		s.EndAudit(entry, status.Code(err).String())
+		return err
+	}
+}
+
+func methodName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/service.GateService/")
+}
+
 var backendMethods = map[string]bool{
 	"/service.GateService/GetStats":                 true,
 	"/service.GateService/GetUserOnlineStats":       true,
@@ -194,6 +413,46 @@ var backendMethods = map[string]bool{
 	"/service.GateService/SyncUser":                 true,
 	"/service.GateService/SyncUsers":                true,
 	"/service.GateService/GetLogs":                  true,
+	"/service.GateService/RemoveUsers":              true,
+	"/service.GateService/ListUsers":                true,
+	"/service.GateService/GetUser":                  true,
+	"/service.GateService/GetRestrictedUsers":       true,
+	"/service.GateService/GetUpcomingExpirations":   true,
+	"/service.GateService/GetEnforcementEvents":     true,
+	"/service.GateService/GetPolicies":              true,
+	"/service.GateService/SetPolicies":              true,
+}
+
+// methodScopes lists the scope an api key needs for each method.
+// Start and Stop are checked in the handler, secondary sessions don't need the lifecycle scope.
+var methodScopes = map[string]config.Scope{
+	"/service.GateService/GetSystemStats":           config.ScopeStats,
+	"/service.GateService/GetBackendStats":          config.ScopeStats,
+	"/service.GateService/GetStats":                 config.ScopeStats,
+	"/service.GateService/GetUserOnlineStats":       config.ScopeStats,
+	"/service.GateService/GetUserOnlineIpListStats": config.ScopeStats,
+	"/service.GateService/ListUsers":                config.ScopeStats,
+	"/service.GateService/GetUser":                  config.ScopeStats,
+	"/service.GateService/GetRestrictedUsers":       config.ScopeStats,
+	"/service.GateService/GetUpcomingExpirations":   config.ScopeStats,
+	"/service.GateService/GetEnforcementEvents":     config.ScopeStats,
+	"/service.GateService/GetPolicies":              config.ScopeStats,
+	"/service.GateService/GetLogs":                  config.ScopeLogs,
+	"/service.GateService/SyncUser":                 config.ScopeUsers,
+	"/service.GateService/SyncUsers":                config.ScopeUsers,
+	"/service.GateService/RemoveUsers":              config.ScopeUsers,
+	"/service.GateService/SetPolicies":              config.ScopeLifecycle,
+	"/service.GateService/GetBans":                  config.ScopeStats,
+	"/service.GateService/ClearBans":                config.ScopeLifecycle,
+	"/service.GateService/GetAuditLog":              config.ScopeLogs,
+}
+
+// controlMethods change the core and are limited to the primary session.
+var controlMethods = map[string]bool{
+	"/service.GateService/SyncUser":    true,
+	"/service.GateService/SyncUsers":   true,
+	"/service.GateService/RemoveUsers": true,
+	"/service.GateService/SetPolicies": true,
 }
 
 func ConditionalMiddleware(s *Service) grpc.UnaryServerInterceptor {
@@ -203,16 +462,28 @@ func ConditionalMiddleware(s *Service) grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
+		if isHealthMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
 		var interceptors []grpc.UnaryServerInterceptor
 
 		interceptors = append(interceptors, LoggingInterceptor(s))
 
 		interceptors = append(interceptors, validateApiKeyMiddleware(s))
 
+		if controller.AuditedMethods[methodName(info.FullMethod)] {
+			interceptors = append(interceptors, auditInterceptor(s))
+		}
+
 		if backendMethods[info.FullMethod] {
 			interceptors = append(interceptors, CheckBackendMiddleware(s))
 		}
 
+		if controlMethods[info.FullMethod] {
+			interceptors = append(interceptors, CheckPrimaryMiddleware(s))
+		}
+
 		chained := grpcmiddleware.ChainUnaryServer(interceptors...)
 		return chained(ctx, req, info, handler)
 	}
@@ -225,16 +496,28 @@ func ConditionalStreamMiddleware(s *Service) grpc.StreamServerInterceptor {
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
+		if isHealthMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
 		var interceptors []grpc.StreamServerInterceptor
 
 		interceptors = append(interceptors, LoggingStreamInterceptor(s))
 
 		interceptors = append(interceptors, validateApiKeyStreamMiddleware(s))
 
+		if controller.AuditedMethods[methodName(info.FullMethod)] {
+			interceptors = append(interceptors, auditStreamInterceptor(s))
+		}
+
 		if backendMethods[info.FullMethod] {
 			interceptors = append(interceptors, CheckBackendStreamMiddleware(s))
 		}
 
+		if controlMethods[info.FullMethod] {
+			interceptors = append(interceptors, CheckPrimaryStreamMiddleware(s))
+		}
+
 		chained := grpcmiddleware.ChainStreamServer(interceptors...)
 		return chained(srv, ss, info, handler)
 	}
@@ -0,0 +1,24 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/controller"
+)
+
+func (s *Service) GetPolicies(ctx context.Context, _ *common.Empty) (*common.Policies, error) {
+	return s.Backend().GetPolicies(ctx)
+}
+
+func (s *Service) SetPolicies(ctx context.Context, policies *common.Policies) (*common.Empty, error) {
+	if err := s.Backend().SetPolicies(ctx, policies); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set policies: %v", err)
+	}
+	controller.Audit(ctx).Changes.CoreRestarted = true
+
+	return &common.Empty{}, nil
+}
@@ -1,10 +1,14 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -15,6 +19,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/Rexa/Gate/common"
 	"github.com/Rexa/Gate/config"
@@ -332,6 +337,120 @@ loop:
 	}
 }
 
+func TestGRPC_StopWithoutLifecycleScope(t *testing.T) {
+	s := sharedTestCtx.service.(*Service)
+	readOnly := &config.ApiKey{Name: "dashboard", Scopes: []config.Scope{config.ScopeStats, config.ScopeLogs}}
+
+	id := s.Attach("127.0.0.1", 0)
+	ctx := controller.WithApiKey(metadata.NewIncomingContext(context.Background(), metadata.Pairs(controller.SessionHeader, id)), readOnly)
+	if _, err := s.Stop(ctx, &common.Empty{}); err != nil {
+		t.Fatalf("A read-only key should end its secondary session: %v", err)
+	}
+
+	if _, err := s.Stop(controller.WithApiKey(context.Background(), readOnly), &common.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected a read-only key to be denied stopping the core, got: %v", err)
+	}
+}
+
+func TestGRPC_SignedSyncUserStream(t *testing.T) {
+	s := New(controller.New(&config.Config{SignedRequests: true}))
+	ctx := controller.WithApiKey(context.Background(), &config.ApiKey{Name: "panel", Scopes: config.AllScopes})
+
+	_, err := validateApiKey(ctx, s, "/service.GateService/SyncUser", nil)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected signed SyncUser streams to be refused, got %v", err)
+	}
+}
+
+func TestGRPC_WebGetBackendStats(t *testing.T) {
+	handler := webHandler(NewGRPCServer(sharedTestCtx.service.(*Service)), nil)
+
+	req := httptest.NewRequest("POST", "/service.GateService/GetBackendStats", bytes.NewReader(frame(0, nil)))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("x-api-key", apiKey.String())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	body := recorder.Body.Bytes()
+	if recorder.Header().Get("Content-Type") != "application/grpc-web+proto" || len(body) < 5 || body[0] != 0 {
+		t.Fatalf("Unexpected gRPC-Web response: %s %q", recorder.Header().Get("Content-Type"), body)
+	}
+
+	var stats common.BackendStatsResponse
+	length := binary.BigEndian.Uint32(body[1:5])
+	if err := proto.Unmarshal(body[5:5+length], &stats); err != nil {
+		t.Fatalf("Failed to decode backend stats: %v", err)
+	}
+	if trailer := body[5+length:]; len(trailer) < 5 || trailer[0] != grpcWebTrailerFlag || !bytes.Contains(trailer, []byte("grpc-status: 0")) {
+		t.Errorf("Unexpected gRPC-Web trailer: %q", trailer)
+	}
+}
+
+func TestGRPC_CamouflageHidesHealth(t *testing.T) {
+	cfg := &config.Config{Camouflage: config.CamouflageNotFound}
+	s := New(controller.New(cfg))
+	handler := HTTPHandler(s, NewGRPCServer(s), cfg)
+
+	req := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(frame(0, nil)))
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	req.Header.Set("Content-Type", "application/grpc")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusNotFound || recorder.Header().Get("Grpc-Status") != "" {
+		t.Fatalf("expected the decoy to answer an unauthenticated health check, got %d %v", recorder.Code, recorder.Header())
+	}
+}
+
+func TestGRPC_CamouflageHidesPreflight(t *testing.T) {
+	cfg := &config.Config{Camouflage: config.CamouflageNotFound, GrpcWeb: true, GrpcWebOrigins: []string{"*"}}
+	s := New(controller.New(cfg))
+	handler := HTTPHandler(s, NewGRPCServer(s), cfg)
+
+	req := httptest.NewRequest(http.MethodOptions, "/service.GateService/GetBackendStats", nil)
+	req.Header.Set("Origin", "https://panel.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type, x-api-key")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusNotFound || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected the decoy to answer a preflight on a camouflaged node, got %d %v", recorder.Code, recorder.Header())
+	}
+}
+
+func TestGRPC_ConnectGetLogsStream(t *testing.T) {
+	handler := webHandler(NewGRPCServer(sharedTestCtx.service.(*Service)), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req := httptest.NewRequest("POST", "/service.GateService/GetLogs", bytes.NewReader(frame(0, []byte("{}")))).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/connect+json")
+	req.Header.Set("x-api-key", apiKey.String())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	// every frame is a log until the deadline ends the stream
+	body := recorder.Body.Bytes()
+	var last byte
+	for len(body) >= 5 {
+		last = body[0]
+		length := binary.BigEndian.Uint32(body[1:5])
+		if last == 0 {
+			fmt.Println("Log detail:", string(body[5:5+length]))
+		}
+		body = body[5+length:]
+	}
+	if last != connectEndStreamFlag {
+		t.Errorf("Expected the stream to end with an end-stream frame, got flag %#x", last)
+	}
+}
+
 func TestGRPC_GetSystemStats(t *testing.T) {
 	ctx, cancel := context.WithTimeout(sharedTestCtx.ctxWithSession, 5*time.Second)
 	defer cancel()
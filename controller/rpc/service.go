@@ -3,47 +3,72 @@ package rpc
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/Rexa/Gate/common"
 	"github.com/Rexa/Gate/config"
 	"github.com/Rexa/Gate/controller"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"log"
 	"net"
+	"net/http"
 )
 
 type Service struct {
 	common.UnimplementedGateServiceServer
-	controller.Controller
+	*controller.Controller
 }
 
-func New(cfg *config.Config) *Service {
+// New creates the gRPC service on top of c, which can be shared with the REST service.
+func New(c *controller.Controller) *Service {
 	return &Service{
-		Controller: *controller.New(cfg),
+		Controller: c,
 	}
 }
 
-func StartGRPCListener(tlsConfig *tls.Config, addr string, cfg *config.Config) (func(ctx context.Context) error, controller.Service, error) {
-	s := New(cfg)
-
-	creds := credentials.NewTLS(tlsConfig)
-
-	// Create the gRPC server with conditional middleware
-	grpcServer := grpc.NewServer(
-		grpc.Creds(creds),
+// NewGRPCServer creates the grpc server with the conditional middleware and registers s and the health service.
+func NewGRPCServer(s *Service, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
 		grpc.UnaryInterceptor(ConditionalMiddleware(s)),
 		grpc.StreamInterceptor(ConditionalStreamMiddleware(s)),
 	)
+	grpcServer := grpc.NewServer(opts...)
 
-	// Register the service
 	common.RegisterGateServiceServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, &healthServer{s: s})
+	return grpcServer
+}
+
+// HTTPHandler serves the grpc server through net/http, with gRPC-Web and Connect calls translated
+// when GRPC_WEB is enabled and all of it behind the decoy in camouflage mode, CORS preflights included.
+func HTTPHandler(s *Service, grpcServer *grpc.Server, cfg *config.Config) http.Handler {
+	var handler http.Handler = grpcServer
+	if cfg.GrpcWeb {
+		handler = webHandler(handler, cfg.GrpcWebOrigins)
+	}
+	if decoy := controller.NewDecoy(cfg); decoy != nil {
+		handler = camouflageHandler(s, handler, decoy, cfg.GrpcWeb)
+	}
+	return handler
+}
+
+func StartGRPCListener(tlsConfig *tls.Config, addr string, cfg *config.Config) (func(ctx context.Context) error, controller.Service, error) {
+	s := New(controller.New(cfg))
+
+	grpcServer := NewGRPCServer(s, grpc.Creds(credentials.NewTLS(tlsConfig)))
 
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
+	// the native grpc transport can't answer with a decoy or speak gRPC-Web and Connect
+	if controller.NewDecoy(cfg) != nil || cfg.GrpcWeb {
+		return serveHTTP(listener, tlsConfig, HTTPHandler(s, grpcServer, cfg)), s, nil
+	}
+
 	go func() {
 		log.Println("gRPC Server listening on", addr)
 		log.Println("Press Ctrl+C to stop")
@@ -71,3 +96,21 @@ func StartGRPCListener(tlsConfig *tls.Config, addr string, cfg *config.Config) (
 		}
 	}, s, nil
 }
+
+// serveHTTP serves the grpc server through net/http, the tls config gets h2 added by ServeTLS.
+func serveHTTP(listener net.Listener, tlsConfig *tls.Config, handler http.Handler) func(ctx context.Context) error {
+	httpServer := &http.Server{
+		TLSConfig: tlsConfig,
+		Handler:   handler,
+	}
+
+	go func() {
+		log.Println("gRPC Server listening on", listener.Addr(), "through net/http")
+		log.Println("Press Ctrl+C to stop")
+		if err := httpServer.ServeTLS(listener, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}()
+
+	return httpServer.Shutdown
+}
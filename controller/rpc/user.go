@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/Rexa/Gate/common"
+	"github.com/Rexa/Gate/controller"
 )
 
 func (s *Service) SyncUser(stream grpc.ClientStreamingServer[common.User, common.Empty]) error {
@@ -24,18 +25,64 @@ func (s *Service) SyncUser(stream grpc.ClientStreamingServer[common.User, common
 		}
 
 		log.Printf("Got user: %v", user.GetEmail())
+		changes := controller.Audit(stream.Context()).Changes
+		changes.Users = append(changes.Users, user.GetEmail())
 
-		if err = s.Backend().SyncUser(stream.Context(), user); err != nil {
+		restarted, err := s.Backend().SyncUser(stream.Context(), user)
+		if restarted {
+			changes.CoreRestarted = true
+		}
+		if err != nil {
 			log.Printf("Error syncing user: %v", err)
 			return status.Errorf(codes.Internal, "failed to update user: %v", err)
 		}
 	}
 }
 
-func (s *Service) SyncUsers(ctx context.Context, users *common.Users) (*common.Empty, error) {
-	if err := s.Backend().SyncUsers(ctx, users.GetUsers()); err != nil {
+func (s *Service) SyncUsers(ctx context.Context, users *common.Users) (*common.SyncUsersResponse, error) {
+	response, err := s.Backend().SyncUsers(ctx, users.GetUsers())
+	if err != nil {
 		return nil, err
 	}
+	controller.AuditSync(ctx, response)
+
+	return response, nil
+}
+
+func (s *Service) RemoveUsers(ctx context.Context, request *common.RemoveUsersRequest) (*common.RemoveUsersResponse, error) {
+	if len(request.GetEmails()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one email is required")
+	}
+
+	response, err := s.Backend().RemoveUsers(ctx, request.GetEmails())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove users: %v", err)
+	}
+	controller.AuditRemove(ctx, response)
+
+	return response, nil
+}
+
+func (s *Service) ListUsers(ctx context.Context, _ *common.Empty) (*common.LoadedUsersResponse, error) {
+	return s.Backend().ListUsers(ctx)
+}
+
+func (s *Service) GetUser(ctx context.Context, request *common.UserRequest) (*common.LoadedUser, error) {
+	if request.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	return s.Backend().GetUser(ctx, request.GetEmail())
+}
+
+func (s *Service) GetRestrictedUsers(ctx context.Context, _ *common.Empty) (*common.RestrictedUsersResponse, error) {
+	return s.Backend().GetRestrictedUsers(ctx)
+}
+
+func (s *Service) GetUpcomingExpirations(ctx context.Context, request *common.ExpirationsRequest) (*common.ExpirationsResponse, error) {
+	return s.Backend().GetUpcomingExpirations(ctx, request.GetWithin())
+}
 
-	return nil, nil
+func (s *Service) GetEnforcementEvents(ctx context.Context, request *common.EnforcementEventsRequest) (*common.EnforcementEventsResponse, error) {
+	return s.Backend().GetEnforcementEvents(ctx, request.GetSince())
 }
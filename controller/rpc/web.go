@@ -0,0 +1,430 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Rexa/Gate/common"
+)
+
+type webProtocol int
+
+const (
+	protocolGrpcWeb webProtocol = iota
+	protocolGrpcWebText
+	protocolConnectUnary
+	protocolConnectStream
+)
+
+const (
+	// flags of the frame that ends a response
+	grpcWebTrailerFlag   = 0x80
+	connectEndStreamFlag = 0x02
+	// maxUnaryBody matches the default receive limit of the grpc server.
+	maxUnaryBody = 4 << 20
+)
+
+// registerJsonCodec lets the grpc server take application/grpc+json, it is only done
+// when the web translation is enabled so native clients can't pick json otherwise.
+var registerJsonCodec sync.Once
+
+// jsonCodec decodes the Connect and gRPC-Web calls with json bodies, they are handed to the grpc server as application/grpc+json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("json codec: %T is not a proto message", v)
+	}
+	return protojson.Marshal(message)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("json codec: %T is not a proto message", v)
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, message)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+// webProtocolOf tells gRPC-Web and Connect calls apart by their content type, the codec is proto or json.
+func webProtocolOf(r *http.Request) (protocol webProtocol, codec string, ok bool) {
+	contentType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	mediaType, codec, _ := strings.Cut(strings.TrimSpace(strings.ToLower(contentType)), "+")
+	if codec == "" {
+		codec = "proto"
+	}
+
+	switch mediaType {
+	case "application/grpc-web":
+		return protocolGrpcWeb, codec, true
+	case "application/grpc-web-text":
+		return protocolGrpcWebText, codec, true
+	case "application/connect":
+		return protocolConnectStream, codec, true
+	case "application/proto":
+		return protocolConnectUnary, "proto", true
+	case "application/json":
+		return protocolConnectUnary, "json", true
+	}
+	return 0, "", false
+}
+
+// webHandler translates gRPC-Web and Connect calls into native gRPC requests for next, so they go through
+// the same interceptors. Browsers from origins are allowed by CORS, "*" allows any.
+// It has to be created before the grpc server serves, the json codec is registered here.
+func webHandler(next http.Handler, origins []string) http.Handler {
+	registerJsonCodec.Do(func() {
+		encoding.RegisterCodec(jsonCodec{})
+	})
+
+	streams := make(map[string]bool)
+	for _, stream := range common.GateService_ServiceDesc.Streams {
+		streams[stream.StreamName] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowOrigin(w, r, origins) && r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		protocol, codec, ok := webProtocolOf(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if codec != "proto" && codec != "json" {
+			http.Error(w, fmt.Sprintf("unsupported codec %q", codec), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		ww := &webResponseWriter{w: w, protocol: protocol, contentType: r.Header.Get("Content-Type"), header: make(http.Header)}
+
+		if protocol == protocolConnectUnary && streams[methodName(r.URL.Path)] {
+			ww.fail(codes.Unimplemented, "streaming methods take the application/connect+proto or application/connect+json content type")
+			return
+		}
+
+		grpcRequest, err := translateRequest(r, protocol, codec)
+		if err != nil {
+			ww.fail(codes.InvalidArgument, err.Error())
+			return
+		}
+
+		next.ServeHTTP(ww, grpcRequest)
+		ww.finish()
+	})
+}
+
+func allowOrigin(w http.ResponseWriter, r *http.Request, origins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !(slices.Contains(origins, "*") || slices.Contains(origins, origin)) {
+		return false
+	}
+
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Add("Vary", "Origin")
+	h.Set("Access-Control-Expose-Headers", "Grpc-Status, Grpc-Message, Grpc-Status-Details-Bin")
+	if r.Method == http.MethodOptions {
+		h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		h.Set("Access-Control-Max-Age", "7200")
+	}
+	return true
+}
+
+func translateRequest(r *http.Request, protocol webProtocol, codec string) (*http.Request, error) {
+	encodingHeader := "Grpc-Encoding"
+	switch protocol {
+	case protocolConnectUnary:
+		encodingHeader = "Content-Encoding"
+	case protocolConnectStream:
+		encodingHeader = "Connect-Content-Encoding"
+	}
+	if encoding := r.Header.Get(encodingHeader); encoding != "" && encoding != "identity" {
+		return nil, fmt.Errorf("compressed requests are not supported, got %s", encoding)
+	}
+
+	grpcRequest := r.Clone(r.Context())
+	grpcRequest.ProtoMajor, grpcRequest.ProtoMinor, grpcRequest.Proto = 2, 0, "HTTP/2.0"
+	grpcRequest.Header.Set("Content-Type", "application/grpc+"+codec)
+	grpcRequest.Header.Del("Content-Length")
+	grpcRequest.ContentLength = -1
+
+	switch protocol {
+	case protocolGrpcWebText:
+		grpcRequest.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+	case protocolConnectUnary:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxUnaryBody+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > maxUnaryBody {
+			return nil, fmt.Errorf("request body is larger than %d bytes", maxUnaryBody)
+		}
+		grpcRequest.Body = io.NopCloser(bytes.NewReader(frame(0, body)))
+	}
+
+	if protocol == protocolConnectUnary || protocol == protocolConnectStream {
+		if timeout := r.Header.Get("Connect-Timeout-Ms"); timeout != "" {
+			ms, err := strconv.ParseUint(timeout, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Connect-Timeout-Ms %q", timeout)
+			}
+			// grpc-timeout takes at most 8 digits
+			if ms <= 99999999 {
+				grpcRequest.Header.Set("Grpc-Timeout", fmt.Sprintf("%dm", ms))
+			} else {
+				grpcRequest.Header.Set("Grpc-Timeout", fmt.Sprintf("%dS", min(ms/1000, 99999999)))
+			}
+		}
+		for key := range grpcRequest.Header {
+			if strings.HasPrefix(key, "Connect-") {
+				grpcRequest.Header.Del(key)
+			}
+		}
+	}
+	return grpcRequest, nil
+}
+
+// frame prefixes a message with the flags and length, the framing gRPC, gRPC-Web and Connect streams share.
+func frame(flags byte, message []byte) []byte {
+	framed := make([]byte, 5, 5+len(message))
+	framed[0] = flags
+	binary.BigEndian.PutUint32(framed[1:], uint32(len(message)))
+	return append(framed, message...)
+}
+
+// webResponseWriter turns the response of the grpc server back into the web protocol.
+// Responses that aren't gRPC, like the decoy or an error of the grpc server, are passed through.
+type webResponseWriter struct {
+	w           http.ResponseWriter
+	protocol    webProtocol
+	contentType string
+	header      http.Header
+	wroteHeader bool
+	passthrough bool
+	// buf holds gRPC-Web text until it is flushed and the whole Connect unary response.
+	buf bytes.Buffer
+}
+
+func (ww *webResponseWriter) Header() http.Header {
+	return ww.header
+}
+
+func (ww *webResponseWriter) WriteHeader(code int) {
+	if ww.wroteHeader {
+		return
+	}
+	ww.wroteHeader = true
+
+	ww.passthrough = code != http.StatusOK || !strings.HasPrefix(ww.header.Get("Content-Type"), "application/grpc")
+	if ww.passthrough {
+		for key, values := range ww.header {
+			ww.w.Header()[key] = values
+		}
+		ww.w.WriteHeader(code)
+		return
+	}
+
+	// the status of a Connect unary call is only known once it ends
+	if ww.protocol != protocolConnectUnary {
+		ww.sendHeader(http.StatusOK, ww.contentType)
+	}
+}
+
+// sendHeader sends the response metadata, the grpc headers are carried in the last frame instead.
+func (ww *webResponseWriter) sendHeader(code int, contentType string) {
+	h := ww.w.Header()
+	for key, values := range ww.header {
+		if key == "Trailer" || key == "Content-Type" || strings.HasPrefix(key, "Grpc-") || strings.HasPrefix(key, http.TrailerPrefix) {
+			continue
+		}
+		h[key] = values
+	}
+	h.Set("Content-Type", contentType)
+	ww.w.WriteHeader(code)
+}
+
+func (ww *webResponseWriter) Write(p []byte) (int, error) {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.passthrough || ww.protocol == protocolGrpcWeb || ww.protocol == protocolConnectStream {
+		return ww.w.Write(p)
+	}
+	return ww.buf.Write(p)
+}
+
+func (ww *webResponseWriter) Flush() {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.protocol == protocolConnectUnary && !ww.passthrough {
+		return
+	}
+
+	if ww.protocol == protocolGrpcWebText && ww.buf.Len() > 0 {
+		_, _ = ww.w.Write([]byte(base64.StdEncoding.EncodeToString(ww.buf.Bytes())))
+		ww.buf.Reset()
+	}
+	if flusher, ok := ww.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// trailers returns the status and the trailer metadata the grpc server set once the call ended.
+func (ww *webResponseWriter) trailers() (codes.Code, string, http.Header) {
+	code := codes.Unknown
+	if value, err := strconv.ParseUint(ww.header.Get("Grpc-Status"), 10, 32); err == nil {
+		code = codes.Code(value)
+	}
+
+	message, err := url.PathUnescape(ww.header.Get("Grpc-Message"))
+	if err != nil {
+		message = ww.header.Get("Grpc-Message")
+	}
+
+	trailers := make(http.Header)
+	for key, values := range ww.header {
+		if name, ok := strings.CutPrefix(key, http.TrailerPrefix); ok {
+			trailers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if details := ww.header.Get("Grpc-Status-Details-Bin"); details != "" {
+		trailers.Set("Grpc-Status-Details-Bin", details)
+	}
+	return code, message, trailers
+}
+
+// finish writes the status once the grpc server returned.
+func (ww *webResponseWriter) finish() {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.passthrough {
+		return
+	}
+
+	code, message, trailers := ww.trailers()
+	switch ww.protocol {
+	case protocolGrpcWeb, protocolGrpcWebText:
+		var block bytes.Buffer
+		fmt.Fprintf(&block, "grpc-status: %d\r\n", code)
+		if message != "" {
+			fmt.Fprintf(&block, "grpc-message: %s\r\n", ww.header.Get("Grpc-Message"))
+		}
+		for key, values := range trailers {
+			for _, value := range values {
+				fmt.Fprintf(&block, "%s: %s\r\n", strings.ToLower(key), value)
+			}
+		}
+		_, _ = ww.Write(frame(grpcWebTrailerFlag, block.Bytes()))
+		ww.Flush()
+
+	case protocolConnectStream:
+		end := connectEndStream{Metadata: trailers}
+		if code != codes.OK {
+			end.Error = &connectError{Code: connectCode(code), Message: message}
+		}
+		body, _ := json.Marshal(end)
+		_, _ = ww.w.Write(frame(connectEndStreamFlag, body))
+
+	case protocolConnectUnary:
+		for key, values := range trailers {
+			ww.w.Header()["Trailer-"+key] = values
+		}
+		if code != codes.OK {
+			ww.fail(code, message)
+			return
+		}
+
+		// the response is a single frame, Connect sends the message without it
+		body := ww.buf.Bytes()
+		if len(body) >= 5 {
+			body = body[5:]
+		}
+		ww.sendHeader(http.StatusOK, "application/"+strings.TrimPrefix(ww.header.Get("Content-Type"), "application/grpc+"))
+		_, _ = ww.w.Write(body)
+	}
+}
+
+// fail answers with an error before or instead of the grpc response.
+func (ww *webResponseWriter) fail(code codes.Code, message string) {
+	switch ww.protocol {
+	case protocolConnectUnary:
+		body, _ := json.Marshal(connectError{Code: connectCode(code), Message: message})
+		ww.sendHeader(common.GrpcCodeToHTTP(code), "application/json")
+		_, _ = ww.w.Write(body)
+
+	case protocolConnectStream:
+		body, _ := json.Marshal(connectEndStream{Error: &connectError{Code: connectCode(code), Message: message}})
+		ww.sendHeader(http.StatusOK, ww.contentType)
+		_, _ = ww.w.Write(frame(connectEndStreamFlag, body))
+
+	default:
+		// a trailers-only response carries the status in the headers
+		h := ww.w.Header()
+		h.Set("Content-Type", ww.contentType)
+		h.Set("Grpc-Status", strconv.Itoa(int(code)))
+		h.Set("Grpc-Message", url.PathEscape(message))
+		ww.w.WriteHeader(http.StatusOK)
+	}
+}
+
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type connectEndStream struct {
+	Error    *connectError `json:"error,omitempty"`
+	Metadata http.Header   `json:"metadata,omitempty"`
+}
+
+var connectCodes = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "data_loss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+func connectCode(code codes.Code) string {
+	if name, ok := connectCodes[code]; ok {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/Rexa/Gate/config"
+)
+
+type apiKeyCtxKey struct{}
+
+// WithApiKey stores the key a request was authenticated with.
+func WithApiKey(ctx context.Context, key *config.ApiKey) context.Context {
+	return context.WithValue(ctx, apiKeyCtxKey{}, key)
+}
+
+// Allows reports whether the key the request was authenticated with has the given scope.
+func Allows(ctx context.Context, scope config.Scope) bool {
+	key, ok := ApiKeyFrom(ctx)
+	return ok && key.Allows(scope)
+}
+
+// ApiKeyFrom returns the key the request was authenticated with, if any.
+func ApiKeyFrom(ctx context.Context) (*config.ApiKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(*config.ApiKey)
+	return key, ok
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionHeader carries the session id returned by Start on every later request.
+const SessionHeader = "x-session-id"
+
+// secondaryKeepAlive is the keep alive of secondary sessions that didn't ask for one,
+// they have to send their id on every request and are closed once they go quiet.
+const secondaryKeepAlive = 60
+
+type session struct {
+	ip          string
+	lastRequest time.Time
+	// detached sessions only release the panel on keep alive timeout, the backend keeps running.
+	detached bool
+	// identified is set once a request carries the session id, panels that never send it are legacy ones.
+	identified bool
+	cancel     context.CancelFunc
+}
+
+// Connect opens the primary session, the one that controls the core.
+// A previous primary session is closed, secondary sessions are left alone.
+func (c *Controller) Connect(ip string, keepAlive uint64, detached bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.endSession(c.primary)
+	c.primary = c.openSession(ip, keepAlive, detached || c.cfg.Detached)
+	return c.primary
+}
+
+// Attach opens a secondary session, it can read stats and logs but doesn't control the core.
+func (c *Controller) Attach(ip string, keepAlive uint64) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if keepAlive == 0 {
+		keepAlive = secondaryKeepAlive
+	}
+	return c.openSession(ip, keepAlive, false)
+}
+
+// EndSession closes a secondary session, the primary one is closed with Disconnect or Release.
+func (c *Controller) EndSession(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id != c.primary {
+		c.endSession(id)
+	}
+}
+
+// IsPrimary reports whether the session controls the core.
+// Requests without a session id control it while there is no session at all,
+// or when they come from the primary panel that never sent its id.
+func (c *Controller) IsPrimary(id, ip string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if id == "" {
+		if len(c.sessions) == 0 {
+			return true
+		}
+		_, ok := c.legacyPrimary(ip)
+		return ok
+	}
+	return id == c.primary
+}
+
+// legacyPrimary returns the primary session if it never sent its id and ip is the one it connected from,
+// requests without an id are only credited to it then. It must be called with mu held.
+func (c *Controller) legacyPrimary(ip string) (*session, bool) {
+	primary, ok := c.sessions[c.primary]
+	if !ok || primary.identified || primary.ip != ip {
+		return nil, false
+	}
+	return primary, true
+}
+
+func (c *Controller) Ip() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if primary, ok := c.sessions[c.primary]; ok {
+		return primary.ip
+	}
+	return ""
+}
+
+// NewRequest keeps the session alive, requests without an id keep the legacy primary session alive.
+func (c *Controller) NewRequest(id, ip string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id == "" {
+		if primary, ok := c.legacyPrimary(ip); ok {
+			primary.lastRequest = time.Now()
+		}
+		return
+	}
+	if s, ok := c.sessions[id]; ok {
+		s.lastRequest = time.Now()
+		s.identified = true
+	}
+}
+
+// openSession must be called with mu held.
+func (c *Controller) openSession(ip string, keepAlive uint64, detached bool) string {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	id := uuid.NewString()
+	c.sessions[id] = &session{
+		ip:          ip,
+		lastRequest: time.Now(),
+		detached:    detached,
+		cancel:      cancel,
+	}
+
+	if keepAlive > 0 {
+		go c.keepAliveTracker(ctx, id, time.Duration(keepAlive)*time.Second)
+	}
+
+	if c.statsCancel == nil {
+		statsCtx, statsCancel := context.WithCancel(context.Background())
+		c.statsCancel = statsCancel
+		go c.recordSystemStats(statsCtx)
+	}
+
+	return id
+}
+
+// endSession must be called with mu held.
+func (c *Controller) endSession(id string) {
+	s, ok := c.sessions[id]
+	if !ok {
+		return
+	}
+
+	s.cancel()
+	delete(c.sessions, id)
+	if id == c.primary {
+		c.primary = ""
+	}
+
+	if len(c.sessions) == 0 && c.statsCancel != nil {
+		c.statsCancel()
+		c.statsCancel = nil
+	}
+}
+
+func (c *Controller) keepAliveTracker(ctx context.Context, id string, keepAlive time.Duration) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.RLock()
+			s, ok := c.sessions[id]
+			expired := ok && time.Since(s.lastRequest) >= keepAlive
+			primary := id == c.primary
+			c.mu.RUnlock()
+
+			if !expired {
+				continue
+			}
+
+			switch {
+			case !primary:
+				log.Println("secondary session closed due to keep alive timeout")
+				c.EndSession(id)
+			case s.detached:
+				log.Println("panel session released due to keep alive timeout, core keeps running")
+				c.Release()
+			default:
+				log.Println("disconnect automatically due to keep alive timeout")
+				c.Disconnect()
+			}
+		}
+	}
+}
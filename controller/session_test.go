@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rexa/Gate/config"
+)
+
+func TestSessions(t *testing.T) {
+	c := New(&config.Config{})
+
+	primary := c.Connect("10.0.0.1", 0, false)
+	if !c.IsPrimary(primary, "10.0.0.1") || !c.IsPrimary("", "10.0.0.1") {
+		t.Fatal("the primary session and requests without a session should control the core")
+	}
+
+	monitor := c.Attach("10.0.0.2", 0)
+	if c.IsPrimary(monitor, "10.0.0.2") {
+		t.Fatal("a secondary session should not control the core")
+	}
+	if c.IsPrimary("", "10.0.0.2") {
+		t.Fatal("requests without a session from another ip should not control the core")
+	}
+
+	c.EndSession(primary)
+	if !c.IsPrimary(primary, "10.0.0.1") {
+		t.Fatal("EndSession should not close the primary session")
+	}
+
+	next := c.Connect("10.0.0.3", 0, false)
+	if c.IsPrimary(primary, "10.0.0.1") || !c.IsPrimary(next, "10.0.0.3") {
+		t.Fatal("a new primary session should replace the previous one")
+	}
+	if c.Ip() != "10.0.0.3" {
+		t.Errorf("unexpected primary ip %q", c.Ip())
+	}
+	if _, ok := c.sessions[monitor]; !ok {
+		t.Fatal("secondary sessions should survive a new primary session")
+	}
+
+	c.EndSession(monitor)
+	c.Release()
+	if len(c.sessions) != 0 || c.statsCancel != nil {
+		t.Fatal("all sessions should be closed")
+	}
+}
+
+func TestLegacyPrimary(t *testing.T) {
+	c := New(&config.Config{})
+	if !c.IsPrimary("", "10.0.0.1") {
+		t.Fatal("requests without a session should control the core when there is no session")
+	}
+
+	primary := c.Connect("10.0.0.1", 0, false)
+	c.Attach("10.0.0.2", 0)
+	c.NewRequest("", "10.0.0.1")
+	if !c.IsPrimary("", "10.0.0.1") {
+		t.Fatal("a panel that never sends its session id should keep control of the core next to a secondary session")
+	}
+
+	quiet := time.Now().Add(-time.Hour)
+	c.mu.Lock()
+	c.sessions[primary].lastRequest = quiet
+	c.mu.Unlock()
+	c.NewRequest("", "10.0.0.2")
+	if !c.sessions[primary].lastRequest.Equal(quiet) {
+		t.Fatal("requests without a session from another ip should not keep the primary session alive")
+	}
+
+	c.NewRequest(primary, "10.0.0.1")
+	if c.IsPrimary("", "10.0.0.1") || !c.IsPrimary(primary, "10.0.0.1") {
+		t.Fatal("requests without a session should be rejected once the primary session sent its id")
+	}
+}
+
+func TestSecondaryKeepAlive(t *testing.T) {
+	c := New(&config.Config{})
+
+	monitor := c.Attach("10.0.0.2", 0)
+	c.mu.Lock()
+	c.sessions[monitor].lastRequest = time.Now().Add(-2 * secondaryKeepAlive * time.Second)
+	c.mu.Unlock()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.RLock()
+		_, open := c.sessions[monitor]
+		c.mu.RUnlock()
+		if !open {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("a quiet secondary session without a keep alive should be closed")
+}
+
+func TestReadyWithoutBackend(t *testing.T) {
+	if err := New(&config.Config{}).Ready(); err == nil {
+		t.Fatal("a node without a started core should not be ready")
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Rexa/Gate/config"
+)
+
+// Headers of a signed request, gRPC calls carry them as metadata.
+const (
+	KeyNameHeader       = "x-api-key-name"
+	TimestampHeader     = "x-timestamp"
+	NonceHeader         = "x-nonce"
+	ContentDigestHeader = "x-content-sha256"
+	SignatureHeader     = "x-signature"
+)
+
+// SignedRequest is what a request signature covers, gRPC calls use POST and the full method as path.
+type SignedRequest struct {
+	Method        string
+	Path          string
+	KeyName       string
+	Timestamp     string
+	Nonce         string
+	ContentDigest string
+	Signature     string
+}
+
+// NewSignedRequest reads the signature headers with get.
+func NewSignedRequest(method, path string, get func(string) string) SignedRequest {
+	return SignedRequest{
+		Method:        method,
+		Path:          path,
+		KeyName:       get(KeyNameHeader),
+		Timestamp:     get(TimestampHeader),
+		Nonce:         get(NonceHeader),
+		ContentDigest: get(ContentDigestHeader),
+		Signature:     get(SignatureHeader),
+	}
+}
+
+// ContentDigest is the hex encoded sha256 of a request body, gRPC calls digest the deterministically
+// marshaled request message and streams an empty body.
+func ContentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the request keyed with the api key.
+func Sign(key uuid.UUID, method, path, contentDigest, timestamp, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(key.String()))
+	mac.Write([]byte(strings.Join([]string{method, path, contentDigest, timestamp, nonce}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// nonceCache remembers the nonces seen within the clock skew window.
+type nonceCache struct {
+	seen      map[string]time.Time
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time), lastPrune: time.Now()}
+}
+
+// add returns false if the nonce is already known.
+func (n *nonceCache) add(nonce string, now, expiresAt time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if now.Sub(n.lastPrune) >= time.Minute {
+		n.lastPrune = now
+		for seen, expiry := range n.seen {
+			if now.After(expiry) {
+				delete(n.seen, seen)
+			}
+		}
+	}
+
+	if expiry, ok := n.seen[nonce]; ok && now.Before(expiry) {
+		return false
+	}
+	n.seen[nonce] = expiresAt
+	return true
+}
+
+// SigningRequired reports whether requests must be signed instead of carrying the api key.
+func (c *Controller) SigningRequired() bool {
+	return c.cfg.SignedRequests
+}
+
+// VerifySignature checks the signature, the clock skew and that the nonce wasn't used before.
+// The caller checks that the body matches the signed content digest.
+func (c *Controller) VerifySignature(req SignedRequest) (*config.ApiKey, error) {
+	if req.KeyName == "" || req.Timestamp == "" || req.Nonce == "" || req.ContentDigest == "" || req.Signature == "" {
+		return nil, errors.New("missing signature headers")
+	}
+
+	timestamp, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid timestamp")
+	}
+
+	now := time.Now()
+	skew := time.Duration(c.cfg.SignatureMaxSkew) * time.Second
+	if diff := now.Sub(time.Unix(timestamp, 0)); diff > skew || diff < -skew {
+		return nil, errors.New("timestamp is outside the allowed clock skew")
+	}
+
+	apiKey, ok := c.cfg.LookupApiKeyByName(req.KeyName)
+	if !ok {
+		return nil, errors.New("unknown api key")
+	}
+
+	expected := Sign(apiKey.Key, req.Method, req.Path, req.ContentDigest, req.Timestamp, req.Nonce)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	// a timestamp is accepted for skew on either side, so the nonce has to be kept that long
+	if !c.nonces.add(req.KeyName+":"+req.Nonce, now, now.Add(2*skew)) {
+		return nil, errors.New("nonce was already used")
+	}
+
+	return apiKey, nil
+}
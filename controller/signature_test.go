@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Rexa/Gate/config"
+)
+
+func TestVerifySignature(t *testing.T) {
+	key := uuid.New()
+	c := New(&config.Config{ApiKey: key, SignedRequests: true, SignatureMaxSkew: 60})
+
+	digest := ContentDigest([]byte(`{"email":"user@example.com"}`))
+	signed := func(timestamp time.Time, nonce string) SignedRequest {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return SignedRequest{
+			Method:        "PUT",
+			Path:          "/user/sync",
+			KeyName:       "default",
+			Timestamp:     ts,
+			Nonce:         nonce,
+			ContentDigest: digest,
+			Signature:     Sign(key, "PUT", "/user/sync", digest, ts, nonce),
+		}
+	}
+
+	req := signed(time.Now(), "n1")
+	if apiKey, err := c.VerifySignature(req); err != nil || apiKey.Key != key {
+		t.Fatalf("a valid signature should be accepted: %v", err)
+	}
+	if _, err := c.VerifySignature(req); err == nil {
+		t.Fatal("a replayed nonce should be rejected")
+	}
+
+	if _, err := c.VerifySignature(signed(time.Now().Add(-2*time.Minute), "n2")); err == nil {
+		t.Fatal("a timestamp outside the skew window should be rejected")
+	}
+
+	tampered := signed(time.Now(), "n3")
+	tampered.Path = "/users/remove"
+	if _, err := c.VerifySignature(tampered); err == nil {
+		t.Fatal("a signature over a different path should be rejected")
+	}
+
+	unknown := signed(time.Now(), "n4")
+	unknown.KeyName = "monitor"
+	if _, err := c.VerifySignature(unknown); err == nil {
+		t.Fatal("an unknown key name should be rejected")
+	}
+}
@@ -11,8 +11,10 @@ import (
 
 	"github.com/rexa-dev/Gate/config"
 	"github.com/rexa-dev/Gate/controller"
+	"github.com/rexa-dev/Gate/controller/mux"
 	"github.com/rexa-dev/Gate/controller/rest"
 	"github.com/rexa-dev/Gate/controller/rpc"
+	"github.com/rexa-dev/Gate/metrics"
 	"github.com/rexa-dev/Gate/tools"
 )
 
@@ -24,19 +26,32 @@ func main() {
 
 	addr := fmt.Sprintf("%s:%d", cfg.GateHost, cfg.ServicePort)
 
-	tlsConfig, err := tools.LoadTLSCredentials(cfg.SslCertFile, cfg.SslKeyFile)
+	tlsConfig, certReloader, err := tools.LoadReloadableTLSCredentials(cfg.SslCertFile, cfg.SslKeyFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if cfg.SslClientCaFile != "" {
+		if err = certReloader.EnableClientAuth(tlsConfig, cfg.SslClientCaFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go certReloader.Watch(watchCtx, 10*time.Second)
+
 	log.Printf("Starting Gate: v%s", controller.GateVersion)
 
 	var shutdownFunc func(ctx context.Context) error
 	var service controller.Service
 
-	if cfg.ServiceProtocol == "rest" {
+	switch cfg.ServiceProtocol {
+	case config.ProtocolRest:
 		shutdownFunc, service, err = rest.StartHttpListener(tlsConfig, addr, cfg)
-	} else {
+	case config.ProtocolBoth:
+		shutdownFunc, service, err = mux.StartListener(tlsConfig, addr, cfg)
+	default:
 		shutdownFunc, service, err = rpc.StartGRPCListener(tlsConfig, addr, cfg)
 	}
 	if err != nil {
@@ -45,9 +60,35 @@ func main() {
 
 	defer service.Disconnect()
 
+	if cfg.MetricsAddr != "" {
+		shutdownMetrics, err := metrics.Listen(cfg.MetricsAddr, service.MetricsHandler(), service.ProbeHandler())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer shutdownMetrics(context.Background())
+	}
+
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 
+	// Reload the certificate, client CAs and api keys on SIGHUP
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for range reloadChan {
+			if err := certReloader.Reload(); err != nil {
+				log.Println("failed to reload tls certificate:", err)
+			} else {
+				log.Println("tls certificate reloaded")
+			}
+			if err := cfg.ReloadApiKeys(); err != nil {
+				log.Println("failed to reload api keys:", err)
+			} else {
+				log.Println("api keys reloaded")
+			}
+		}
+	}()
+
 	// Wait for interrupt
 	<-stopChan
 	log.Println("Shutting down server...")
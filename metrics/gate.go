@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	Requests = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "gate_requests_total",
+		Help: "Control api requests by method and result code.",
+	}, []string{"method", "code"})
+	RequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gate_request_duration_seconds",
+		Help:    "Latency of control api requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+	CoreRestarts = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Name: "gate_core_restarts_total",
+		Help: "Restarts of the core, including the ones after failed health checks.",
+	})
+	HealthCheckFailures = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Name: "gate_health_check_failures_total",
+		Help: "Failed health checks of the core.",
+	})
+)
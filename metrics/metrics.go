@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the Gate metrics, it is kept apart from the default registry
+// so the endpoint only serves what Gate registers itself.
+var Registry = prometheus.NewRegistry()
+
+// Handler serves the registered metrics along with the ones collector gathers at scrape time.
+func Handler(collector prometheus.Collector) http.Handler {
+	scraped := prometheus.NewRegistry()
+	if collector != nil {
+		scraped.MustRegister(collector)
+	}
+
+	return promhttp.HandlerFor(prometheus.Gatherers{Registry, scraped}, promhttp.HandlerOpts{
+		ErrorLog:      log.Default(),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// Listen serves the handler on /metrics and the probes on /healthz and /readyz over plain http,
+// the address should not be reachable from outside.
+func Listen(addr string, handler, probes http.Handler) (func(ctx context.Context) error, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	mux.Handle("/healthz", probes)
+	mux.Handle("/readyz", probes)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	go func() {
+		log.Println("Metrics listening on", addr)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server error: %v", err)
+		}
+	}()
+
+	return server.Shutdown, nil
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+func TestHandler(t *testing.T) {
+	requests := promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total", Help: "Test requests."}, []string{"method", "code"})
+	requests.WithLabelValues("SyncUser", "OK").Inc()
+	requests.WithLabelValues("SyncUser", "OK").Inc()
+	requests.WithLabelValues("Start", "Unavailable").Inc()
+
+	latency := promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{Name: "test_latency_seconds", Help: "Test latency.", Buckets: []float64{0.1, 1}}, []string{"method"})
+	latency.WithLabelValues("Start").Observe(0.5)
+
+	traffic := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_traffic_bytes_total", Help: "Test traffic."}, []string{"user", "direction"})
+	traffic.WithLabelValues(`a"b`, "uplink").Add(1024)
+
+	recorder := httptest.NewRecorder()
+	Handler(traffic).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	for _, line := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{code="Unavailable",method="Start"} 1`,
+		`test_requests_total{code="OK",method="SyncUser"} 2`,
+		`test_latency_seconds_bucket{method="Start",le="0.1"} 0`,
+		`test_latency_seconds_bucket{method="Start",le="1"} 1`,
+		`test_latency_seconds_bucket{method="Start",le="+Inf"} 1`,
+		`test_latency_seconds_sum{method="Start"} 0.5`,
+		`test_traffic_bytes_total{direction="uplink",user="a\"b"} 1024`,
+		"gate_core_restarts_total 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, body)
+		}
+	}
+}
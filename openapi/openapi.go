@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	contentTypeJSON  = "application/json"
+	contentTypeProto = "application/x-protobuf"
+	errorSchema      = "Status"
+)
+
+// Info is the top level description of the document.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+	// ApiKeyHeader is the header every operation authenticates with.
+	ApiKeyHeader string
+}
+
+// Operation describes one endpoint, messages are documented with their protojson mapping.
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	// Query fields are documented as query parameters, limited to QueryFields when set.
+	Query       proto.Message
+	QueryFields []string
+	Body        proto.Message
+	Response    proto.Message
+	// EventStream documents a text/event-stream response instead of Response.
+	EventStream bool
+}
+
+type object = map[string]any
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Document builds an OpenAPI 3 document, request and response bodies can be json or protobuf
+// and errors are google.rpc.Status json bodies.
+func Document(info Info, operations []Operation) ([]byte, error) {
+	schemas := object{
+		errorSchema: object{
+			"type":        "object",
+			"description": "An error with its gRPC status code.",
+			"properties": object{
+				"code":    object{"type": "integer", "format": "int32"},
+				"message": object{"type": "string"},
+			},
+		},
+	}
+
+	paths := object{}
+	for _, op := range operations {
+		item, ok := paths[op.Path].(object)
+		if !ok {
+			item = object{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = operation(op, schemas)
+	}
+
+	return json.MarshalIndent(object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"security": []object{{"apiKey": []string{}}},
+		"paths":    paths,
+		"components": object{
+			"schemas": schemas,
+			"securitySchemes": object{
+				"apiKey": object{"type": "apiKey", "in": "header", "name": info.ApiKeyHeader},
+			},
+		},
+	}, "", "  ")
+}
+
+func operation(op Operation, schemas object) object {
+	parameters := []object{}
+	for _, match := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		parameters = append(parameters, object{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   object{"type": "string"},
+		})
+	}
+
+	if op.Query != nil {
+		fields := op.Query.ProtoReflect().Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if len(op.QueryFields) > 0 && !slices.Contains(op.QueryFields, string(fd.Name())) {
+				continue
+			}
+			parameters = append(parameters, object{
+				"name":   string(fd.Name()),
+				"in":     "query",
+				"schema": fieldSchema(fd, schemas),
+			})
+		}
+	}
+
+	result := object{
+		"summary":     op.Summary,
+		"operationId": operationId(op),
+		"parameters":  parameters,
+		"responses": object{
+			"200": object{"description": "OK", "content": responseContent(op, schemas)},
+			"default": object{
+				"description": "Error",
+				"content":     object{contentTypeJSON: object{"schema": ref(errorSchema)}},
+			},
+		},
+	}
+	if op.Description != "" {
+		result["description"] = op.Description
+	}
+	if op.Body != nil {
+		result["requestBody"] = object{"required": true, "content": messageContent(op.Body, schemas)}
+	}
+	return result
+}
+
+func responseContent(op Operation, schemas object) object {
+	if op.EventStream {
+		return object{"text/event-stream": object{"schema": object{"type": "string"}}}
+	}
+	return messageContent(op.Response, schemas)
+}
+
+func messageContent(message proto.Message, schemas object) object {
+	schema := messageRef(message.ProtoReflect().Descriptor(), schemas)
+	return object{
+		contentTypeJSON:  object{"schema": schema},
+		contentTypeProto: object{"schema": schema},
+	}
+}
+
+// operationId turns "GET /stats/users/{email}" into "getStatsUsersByEmail".
+func operationId(op Operation) string {
+	id := strings.ToLower(op.Method)
+	for _, segment := range strings.Split(op.Path, "/") {
+		if segment == "" {
+			continue
+		}
+		if match := pathParam.FindStringSubmatch(segment); match != nil {
+			id += "By" + camel(match[1])
+			continue
+		}
+		id += camel(segment)
+	}
+	return id
+}
+
+func camel(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// messageRef adds the schema of the message and the ones it refers to.
+func messageRef(md protoreflect.MessageDescriptor, schemas object) object {
+	name := string(md.Name())
+	if _, ok := schemas[name]; ok {
+		return ref(name)
+	}
+
+	properties := object{}
+	schema := object{"type": "object", "properties": properties}
+	// added before the fields so recursive messages terminate
+	schemas[name] = schema
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[fd.JSONName()] = fieldSchema(fd, schemas)
+	}
+	return ref(name)
+}
+
+func fieldSchema(fd protoreflect.FieldDescriptor, schemas object) object {
+	switch {
+	case fd.IsMap():
+		return object{"type": "object", "additionalProperties": valueSchema(fd.MapValue(), schemas)}
+	case fd.IsList():
+		return object{"type": "array", "items": valueSchema(fd, schemas)}
+	default:
+		return valueSchema(fd, schemas)
+	}
+}
+
+// valueSchema follows the protojson mapping, 64 bit integers are strings and enums their names.
+func valueSchema(fd protoreflect.FieldDescriptor, schemas object) object {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return object{"type": "boolean"}
+	case protoreflect.StringKind:
+		return object{"type": "string"}
+	case protoreflect.BytesKind:
+		return object{"type": "string", "format": "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return object{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return object{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return object{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return object{"type": "string", "format": "uint64"}
+	case protoreflect.FloatKind:
+		return object{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return object{"type": "number", "format": "double"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, values.Len())
+		for i := range names {
+			names[i] = string(values.Get(i).Name())
+		}
+		return object{"type": "string", "enum": names}
+	default:
+		return messageRef(fd.Message(), schemas)
+	}
+}
+
+// Handler serves the document as json.
+func Handler(document []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write(document)
+	})
+}
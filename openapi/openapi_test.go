@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Rexa/Gate/common"
+)
+
+func TestDocument(t *testing.T) {
+	document, err := Document(Info{Title: "test", Version: "1", ApiKeyHeader: "x-api-key"}, []Operation{
+		{Method: "GET", Path: "/stats/users/{email}", Query: &common.StatRequest{}, QueryFields: []string{"reset"}, Response: &common.StatResponse{}},
+		{Method: "PUT", Path: "/users/{email}", Body: &common.User{}, Response: &common.Empty{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationId string `json:"operationId"`
+			Parameters  []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			RequestBody map[string]any `json:"requestBody"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err = json.Unmarshal(document, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := doc.Paths["/stats/users/{email}"]["get"]
+	if stats.OperationId != "getStatsUsersByEmail" {
+		t.Errorf("operationId = %q", stats.OperationId)
+	}
+	if len(stats.Parameters) != 2 || stats.Parameters[0].In != "path" || stats.Parameters[1].Name != "reset" {
+		t.Errorf("parameters = %+v", stats.Parameters)
+	}
+	if doc.Paths["/users/{email}"]["put"].RequestBody == nil {
+		t.Error("request body is not documented")
+	}
+
+	// nested messages are collected and 64 bit integers follow the protojson mapping
+	for _, name := range []string{"StatResponse", "Stat", "User", "Proxy", "Vmess", "Status"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+	if value := doc.Components.Schemas["Stat"].Properties["value"]; value["type"] != "string" || value["format"] != "int64" {
+		t.Errorf("int64 field documented as %v", value)
+	}
+}
@@ -1,11 +1,14 @@
 package tools
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -22,6 +25,184 @@ func LoadTLSCredentials(cert, key string) (*tls.Config, error) {
 	return config, nil
 }
 
+// CertReloader serves the server certificate through GetCertificate so it can be swapped
+// without restarting the listener, the client CAs set by EnableClientAuth are reloaded with it.
+type CertReloader struct {
+	certFile  string
+	keyFile   string
+	caFile    string
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+	mu        sync.RWMutex
+}
+
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate, key and client CA files again, the current ones are kept on error.
+func (r *CertReloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	caFile := r.caFile
+	r.mu.RUnlock()
+
+	var clientCAs *x509.CertPool
+	if caFile != "" {
+		if clientCAs, err = loadClientCAs(caFile); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTime = modTime
+	return nil
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch reloads the certificate whenever one of the files changes.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.latestModTime()
+			if err != nil {
+				continue
+			}
+
+			r.mu.RLock()
+			changed := modTime.After(r.modTime)
+			r.mu.RUnlock()
+
+			if !changed {
+				continue
+			}
+			if err = r.Reload(); err != nil {
+				log.Println("failed to reload tls certificate:", err)
+				continue
+			}
+			log.Println("tls certificate reloaded")
+		}
+	}
+}
+
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	files := []string{r.certFile, r.keyFile}
+	r.mu.RLock()
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	r.mu.RUnlock()
+
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// LoadReloadableTLSCredentials is LoadTLSCredentials with a certificate that can be reloaded.
+func LoadReloadableTLSCredentials(cert, key string) (*tls.Config, *CertReloader, error) {
+	reloader, err := NewCertReloader(cert, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     tls.NoClientCert,
+	}
+	return config, reloader, nil
+}
+
+// EnableClientAuth makes the server require a client certificate signed by one of the CAs in caFile.
+// The chain is verified by the reloader instead of the tls package so reloaded CAs apply to new connections,
+// it runs in VerifyConnection because VerifyPeerCertificate is skipped when a session is resumed.
+func (r *CertReloader) EnableClientAuth(config *tls.Config, caFile string) error {
+	clientCAs, err := loadClientCAs(caFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.caFile = caFile
+	r.clientCAs = clientCAs
+	r.mu.Unlock()
+
+	config.ClientAuth = tls.RequireAnyClientCert
+	config.VerifyConnection = r.verifyClientCert
+	return nil
+}
+
+func (r *CertReloader) verifyClientCert(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("no client certificate")
+	}
+
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	r.mu.RLock()
+	clientCAs := r.clientCAs
+	r.mu.RUnlock()
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+func loadClientCAs(caFile string) (*x509.CertPool, error) {
+	pemClientCA, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %v", err)
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(pemClientCA) {
+		return nil, fmt.Errorf("failed to add client CA certificates")
+	}
+	return certPool, nil
+}
+
 func LoadClientPool(cert string) (*x509.CertPool, error) {
 	pemServerCA, err := os.ReadFile(cert)
 	if err != nil {
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, notAfter time.Time, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+func writePem(t *testing.T, file, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestReloader(t *testing.T, ca *testCert) (*CertReloader, *tls.Config, *x509.CertPool, string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "ssl_cert.pem")
+	keyFile := filepath.Join(dir, "ssl_key.pem")
+	caFile := filepath.Join(dir, "ssl_client_cert.pem")
+
+	server := newTestCert(t, "localhost", ca, time.Now().Add(time.Hour), x509.ExtKeyUsageServerAuth)
+	keyDer, err := x509.MarshalECPrivateKey(server.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePem(t, certFile, "CERTIFICATE", server.der)
+	writePem(t, keyFile, "EC PRIVATE KEY", keyDer)
+	writePem(t, caFile, "CERTIFICATE", ca.der)
+
+	config, reloader, err := LoadReloadableTLSCredentials(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = reloader.EnableClientAuth(config, caFile); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return reloader, config, roots, caFile
+}
+
+// handshake connects a client to the server config and reports whether the session was resumed.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) (bool, error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type result struct {
+		resumed bool
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer serverConn.Close()
+
+		conn := tls.Server(serverConn, server)
+		if err = conn.Handshake(); err != nil {
+			done <- result{err: err}
+			return
+		}
+		// the session ticket is sent ahead of this byte, reading it lets the client store the ticket
+		_, err = conn.Write([]byte{1})
+		done <- result{resumed: conn.ConnectionState().DidResume, err: err}
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := tls.Client(clientConn, client)
+	if err = conn.Handshake(); err == nil {
+		conn.Read(make([]byte, 1))
+	}
+	conn.Close()
+
+	r := <-done
+	return r.resumed, r.err
+}
+
+func TestClientAuth(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, time.Now().Add(time.Hour), 0)
+	_, config, roots, _ := newTestReloader(t, ca)
+
+	client := newTestCert(t, "panel", ca, time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	if _, err := handshake(t, config, &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{client.tlsCertificate()}}); err != nil {
+		t.Fatalf("a client certificate signed by the CA should be accepted: %v", err)
+	}
+
+	if _, err := handshake(t, config, &tls.Config{RootCAs: roots, ServerName: "localhost"}); err == nil {
+		t.Fatal("a client without a certificate should be rejected")
+	}
+
+	other := newTestCert(t, "other", nil, time.Now().Add(time.Hour), 0)
+	stranger := newTestCert(t, "panel", other, time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	if _, err := handshake(t, config, &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{stranger.tlsCertificate()}}); err == nil {
+		t.Fatal("a client certificate signed by another CA should be rejected")
+	}
+
+	expired := newTestCert(t, "panel", ca, time.Now().Add(-time.Hour), x509.ExtKeyUsageClientAuth)
+	if _, err := handshake(t, config, &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{expired.tlsCertificate()}}); err == nil {
+		t.Fatal("an expired client certificate should be rejected")
+	}
+}
+
+func TestClientAuthReload(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, time.Now().Add(time.Hour), 0)
+	reloader, config, roots, caFile := newTestReloader(t, ca)
+
+	client := newTestCert(t, "panel", ca, time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	clientConfig := &tls.Config{
+		RootCAs:            roots,
+		ServerName:         "localhost",
+		Certificates:       []tls.Certificate{client.tlsCertificate()},
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+	if _, err := handshake(t, config, clientConfig); err != nil {
+		t.Fatalf("a client certificate signed by the CA should be accepted: %v", err)
+	}
+	resumed, err := handshake(t, config, clientConfig)
+	if err != nil {
+		t.Fatalf("a resumed session should be accepted: %v", err)
+	}
+	if !resumed {
+		t.Fatal("the client should resume its session")
+	}
+
+	next := newTestCert(t, "ca", nil, time.Now().Add(time.Hour), 0)
+	writePem(t, caFile, "CERTIFICATE", next.der)
+	if err = reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = handshake(t, config, clientConfig); err == nil {
+		t.Fatal("a resumed session should be rejected once its CA is removed")
+	}
+}